@@ -1,19 +1,47 @@
 package main
 
 import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	_ "embed"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/mail"
+	"net/textproto"
+	"net/url"
 	"os"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -21,6 +49,9 @@ import (
 	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/http3"
 	ginprometheus "github.com/zsais/go-gin-prometheus"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/publicsuffix"
 )
 
 // getenvDefault returns env var or default if unset
@@ -91,17 +122,19 @@ type DNSInfo struct {
 
 // IPInfo represents IP address information
 type IPInfo struct {
-	Input        string   `json:"input"`
-	IsDomain     bool     `json:"is_domain"`
-	ResolvedIPs  []string `json:"resolved_ips,omitempty"`
-	IP           string   `json:"ip,omitempty"`
-	Country      string   `json:"country"`
-	Region       string   `json:"region"`
-	City         string   `json:"city"`
-	ISP          string   `json:"isp"`
-	Organization string   `json:"organization"`
-	Timezone     string   `json:"timezone"`
-	Error        string   `json:"error,omitempty"`
+	Input        string            `json:"input"`
+	IsDomain     bool              `json:"is_domain"`
+	ResolvedIPs  []string          `json:"resolved_ips,omitempty"`
+	IP           string            `json:"ip,omitempty"`
+	Country      string            `json:"country"`
+	Region       string            `json:"region"`
+	City         string            `json:"city"`
+	ISP          string            `json:"isp"`
+	Organization string            `json:"organization"`
+	ASN          uint              `json:"asn,omitempty"`
+	Timezone     string            `json:"timezone"`
+	Reputation   *IPReputationInfo `json:"reputation,omitempty"`
+	Error        string            `json:"error,omitempty"`
 }
 
 // HSTSInfo represents HTTP Strict Transport Security information
@@ -168,66 +201,111 @@ type SitemapInfo struct {
 
 // WebSettingsInfo represents web server settings and headers
 type WebSettingsInfo struct {
-	Domain        string            `json:"domain"`
-	StatusCode    int               `json:"status_code"`
-	Headers       map[string]string `json:"headers"`
-	Server        string            `json:"server"`
-	ContentType   string            `json:"content_type"`
-	ContentLength int64             `json:"content_length"`
-	LastModified  string            `json:"last_modified"`
-	ETag          string            `json:"etag"`
-	RedirectURL   string            `json:"redirect_url,omitempty"`
-	HSTS          HSTSInfo          `json:"hsts"`
-	ResponseTime  int64             `json:"response_time_ms"`
-	Error         string            `json:"error,omitempty"`
+	Domain          string              `json:"domain"`
+	StatusCode      int                 `json:"status_code"`
+	Headers         map[string]string   `json:"headers"`
+	Server          string              `json:"server"`
+	ContentType     string              `json:"content_type"`
+	ContentLength   int64               `json:"content_length"`
+	LastModified    string              `json:"last_modified"`
+	ETag            string              `json:"etag"`
+	Request         *WebRequestOptions  `json:"request,omitempty"`
+	RedirectURL     string              `json:"redirect_url,omitempty"`
+	Redirects       RedirectChainInfo   `json:"redirects"`
+	HSTS            HSTSInfo            `json:"hsts"`
+	SecurityHeaders SecurityHeadersInfo `json:"security_headers"`
+	Cookies         CookieAuditInfo     `json:"cookies"`
+	Technologies    []Technology        `json:"technologies"`
+	Timing          RequestTiming       `json:"timing"`
+	ResponseTime    int64               `json:"response_time_ms"`
+	Error           string              `json:"error,omitempty"`
 }
 
 // EmailConfigInfo represents email authentication configuration
 type EmailConfigInfo struct {
-	Domain string    `json:"domain"`
-	SPF    SPFInfo   `json:"spf"`
-	DKIM   DKIMInfo  `json:"dkim"`
-	DMARC  DMARCInfo `json:"dmarc"`
-	BIMI   BIMIInfo  `json:"bimi"`
-	Error  string    `json:"error,omitempty"`
+	Domain string     `json:"domain"`
+	SPF    SPFInfo    `json:"spf"`
+	DKIM   DKIMInfo   `json:"dkim"`
+	DMARC  DMARCInfo  `json:"dmarc"`
+	BIMI   BIMIInfo   `json:"bimi"`
+	MTASTS MTASTSInfo `json:"mta_sts"`
+	TLSRPT TLSRPTInfo `json:"tls_rpt"`
+	Error  string     `json:"error,omitempty"`
 }
 
 // SPFInfo represents SPF (Sender Policy Framework) information
 type SPFInfo struct {
-	Configured bool   `json:"configured"`
-	Record     string `json:"record,omitempty"`
-	Valid      bool   `json:"valid"`
-	Details    string `json:"details,omitempty"`
-	Error      string `json:"error,omitempty"`
+	Configured   bool     `json:"configured"`
+	Record       string   `json:"record,omitempty"`
+	Records      []string `json:"records,omitempty"`
+	Valid        bool     `json:"valid"`
+	Tree         *SPFNode `json:"tree,omitempty"`
+	DNSLookups   int      `json:"dns_lookups"`
+	VoidLookups  int      `json:"void_lookups"`
+	AllQualifier string   `json:"all_qualifier,omitempty"`
+	IPv4         []string `json:"ipv4,omitempty"`
+	IPv6         []string `json:"ipv6,omitempty"`
+	Errors       []string `json:"errors,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+	Details      string   `json:"details,omitempty"`
+	Error        string   `json:"error,omitempty"`
 }
 
 // DKIMInfo represents DKIM (DomainKeys Identified Mail) information
 type DKIMInfo struct {
-	Configured bool     `json:"configured"`
-	Selectors  []string `json:"selectors"`
-	Valid      bool     `json:"valid"`
-	Details    string   `json:"details,omitempty"`
-	Error      string   `json:"error,omitempty"`
+	Configured bool          `json:"configured"`
+	Selectors  []string      `json:"selectors"`
+	Keys       []DKIMKeyInfo `json:"keys"`
+	Tried      int           `json:"selectors_tried"`
+	Failed     []string      `json:"selectors_failed,omitempty"` // lookups that returned a DNS error
+	Valid      bool          `json:"valid"`
+	Details    string        `json:"details,omitempty"`
+	Error      string        `json:"error,omitempty"`
 }
 
 // DMARCInfo represents DMARC (Domain-based Message Authentication) information
 type DMARCInfo struct {
-	Configured bool   `json:"configured"`
-	Record     string `json:"record,omitempty"`
-	Policy     string `json:"policy,omitempty"` // none, quarantine, reject
-	Valid      bool   `json:"valid"`
-	Details    string `json:"details,omitempty"`
-	Error      string `json:"error,omitempty"`
+	Configured      bool              `json:"configured"`
+	Domain          string            `json:"domain,omitempty"` // where the record was found
+	OrgDomain       string            `json:"org_domain,omitempty"`
+	Inherited       bool              `json:"inherited"`
+	Record          string            `json:"record,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
+	Policy          string            `json:"policy,omitempty"` // none, quarantine, reject
+	SubdomainPolicy string            `json:"subdomain_policy,omitempty"`
+	EffectivePolicy string            `json:"effective_policy,omitempty"`
+	Percentage      int               `json:"pct"`
+	RUA             []DMARCReportURI  `json:"rua,omitempty"`
+	RUF             []DMARCReportURI  `json:"ruf,omitempty"`
+	ADKIM           string            `json:"adkim,omitempty"`
+	ASPF            string            `json:"aspf,omitempty"`
+	FailureOptions  string            `json:"fo,omitempty"`
+	ReportFormat    string            `json:"rf,omitempty"`
+	ReportInterval  int               `json:"ri,omitempty"`
+	Valid           bool              `json:"valid"`
+	Errors          []string          `json:"errors,omitempty"`
+	Warnings        []string          `json:"warnings,omitempty"`
+	Details         string            `json:"details,omitempty"`
+	Error           string            `json:"error,omitempty"`
 }
 
 // BIMIInfo represents BIMI (Brand Indicators for Message Identification) information
 type BIMIInfo struct {
-	Configured bool   `json:"configured"`
-	Record     string `json:"record,omitempty"`
-	LogoURL    string `json:"logo_url,omitempty"`
-	Valid      bool   `json:"valid"`
-	Details    string `json:"details,omitempty"`
-	Error      string `json:"error,omitempty"`
+	Configured    bool                 `json:"configured"`
+	Domain        string               `json:"domain,omitempty"` // where the record was found
+	Record        string               `json:"record,omitempty"`
+	Tags          map[string]string    `json:"tags,omitempty"`
+	LogoURL       string               `json:"logo_url,omitempty"`
+	AuthorityURL  string               `json:"authority_url,omitempty"`
+	Logo          *BIMILogoInfo        `json:"logo,omitempty"`
+	Certificate   *BIMICertificateInfo `json:"certificate,omitempty"`
+	DMARCPolicy   string               `json:"dmarc_policy,omitempty"`
+	DMARCEnforced bool                 `json:"dmarc_enforced"`
+	Valid         bool                 `json:"valid"`
+	Errors        []string             `json:"errors,omitempty"`
+	Warnings      []string             `json:"warnings,omitempty"`
+	Details       string               `json:"details,omitempty"`
+	Error         string               `json:"error,omitempty"`
 }
 
 // APIResponse represents a standard API response
@@ -578,37 +656,78 @@ func lookupGeoIP(ipAddr string) (country, region, city, isp, organization, timez
 	return
 }
 
+// lookupASN returns the autonomous system number and organization for an IP
+func lookupASN(ipAddr string) (uint, string) {
+	asnReader := getGeoIPASNReader()
+	if asnReader == nil {
+		return 0, ""
+	}
+
+	ip := net.ParseIP(ipAddr)
+	if ip == nil {
+		return 0, ""
+	}
+
+	geoIPMutex.RLock()
+	record, err := asnReader.ASN(ip)
+	geoIPMutex.RUnlock()
+	if err != nil {
+		return 0, ""
+	}
+
+	return record.AutonomousSystemNumber, record.AutonomousSystemOrganization
+}
+
 // TTLs per route
 var routeTTL = map[string]time.Duration{
-	"/api/v1/ssl":          5 * time.Minute,
-	"/api/v1/http3":        2 * time.Minute,
-	"/api/v1/dns":          2 * time.Minute,
-	"/api/v1/ip":           1 * time.Minute,
-	"/api/v1/my-ip":        30 * time.Second, // Shorter TTL since it's user-specific
-	"/api/v1/web-settings": 1 * time.Minute,
-	"/api/v1/email-config": 10 * time.Minute,
-	"/api/v1/blocklist":    10 * time.Minute,
-	"/api/v1/robots-txt":   10 * time.Minute,
-	"/api/v1/sitemap":      10 * time.Minute,
-	"/api/v1/og-image":     10 * time.Minute,
+	"/api/v1/ssl":                 5 * time.Minute,
+	"/api/v1/http3":               2 * time.Minute,
+	"/api/v1/dns":                 2 * time.Minute,
+	"/api/v1/ip":                  1 * time.Minute,
+	"/api/v1/my-ip":               30 * time.Second, // Shorter TTL since it's user-specific
+	"/api/v1/web-settings":        1 * time.Minute,
+	"/api/v1/security-headers":    1 * time.Minute,
+	"/api/v1/email-config":        10 * time.Minute,
+	"/api/v1/spf":                 10 * time.Minute,
+	"/api/v1/spf/check":           5 * time.Minute,
+	"/api/v1/dkim":                10 * time.Minute,
+	"/api/v1/dmarc":               10 * time.Minute,
+	"/api/v1/bimi":                10 * time.Minute,
+	"/api/v1/smtp":                5 * time.Minute,
+	"/api/v1/deliverability":      10 * time.Minute,
+	"/api/v1/blocklist":           10 * time.Minute,
+	"/api/v1/robots-txt":          10 * time.Minute,
+	"/api/v1/sitemap":             10 * time.Minute,
+	"/api/v1/og-image":            10 * time.Minute,
+	"/api/v1/whois":               1 * time.Hour,
+	"/api/v1/ports":               1 * time.Minute,
+	"/api/v1/diagnostics":         1 * time.Minute,
+	"/api/v1/compression-caching": 5 * time.Minute,
+	"/api/v1/cors":                5 * time.Minute,
+	"/api/v1/http-methods":        5 * time.Minute,
+	"/api/v1/well-known":          10 * time.Minute,
+	"/api/v1/technologies":        10 * time.Minute,
 }
 
 func cacheKey(route string, q map[string]string) string {
-	// build deterministic key
+	// build deterministic key (map iteration order is random, so sort the params)
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	b := strings.Builder{}
 	b.WriteString(route)
 	if len(q) > 0 {
 		b.WriteString("?")
-		first := true
-		for k, v := range q {
-			if !first {
+		for i, k := range keys {
+			if i > 0 {
 				b.WriteString("&")
-			} else {
-				first = false
 			}
 			b.WriteString(k)
 			b.WriteString("=")
-			b.WriteString(v)
+			b.WriteString(q[k])
 		}
 	}
 	return b.String()
@@ -853,6 +972,212 @@ func parseHSTSHeader(header string) HSTSInfo {
 	return info
 }
 
+// -----------------------------
+// DNSBL / IP reputation
+// -----------------------------
+
+// DNSBLZone describes a DNS-based blocklist and the meaning of its 127.0.0.x return codes
+type DNSBLZone struct {
+	Name  string
+	Zone  string
+	Codes map[string]string
+}
+
+// DNSBLResult represents the listing status of an IP on a single DNSBL
+type DNSBLResult struct {
+	Name        string   `json:"name"`
+	Zone        string   `json:"zone"`
+	Listed      bool     `json:"listed"`
+	ReturnCodes []string `json:"return_codes,omitempty"`
+	Reasons     []string `json:"reasons,omitempty"`
+	Text        string   `json:"text,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// IPReputationInfo represents DNSBL reputation information for an IP address
+type IPReputationInfo struct {
+	Checked     int           `json:"checked"`
+	ListedCount int           `json:"listed_count"`
+	Results     []DNSBLResult `json:"results"`
+}
+
+// defaultDNSBLZones are queried unless DNSBL_ZONES overrides them
+var defaultDNSBLZones = []DNSBLZone{
+	{
+		Name: "Spamhaus ZEN",
+		Zone: "zen.spamhaus.org",
+		Codes: map[string]string{
+			"127.0.0.2":       "SBL: Spamhaus SBL data",
+			"127.0.0.3":       "SBL: Spamhaus SBL CSS data",
+			"127.0.0.4":       "XBL: exploited host (CBL)",
+			"127.0.0.5":       "XBL: exploited host",
+			"127.0.0.6":       "XBL: exploited host",
+			"127.0.0.7":       "XBL: exploited host",
+			"127.0.0.9":       "SBL: Spamhaus DROP/EDROP data",
+			"127.0.0.10":      "PBL: ISP maintained end-user range",
+			"127.0.0.11":      "PBL: Spamhaus maintained end-user range",
+			"127.255.255.252": "Error: typing error in DNSBL name",
+			"127.255.255.254": "Error: query via public/open resolver",
+			"127.255.255.255": "Error: excessive number of queries",
+		},
+	},
+	{
+		Name: "Barracuda",
+		Zone: "b.barracudacentral.org",
+		Codes: map[string]string{
+			"127.0.0.2": "Listed on Barracuda Reputation Block List",
+		},
+	},
+	{
+		Name: "SpamCop",
+		Zone: "bl.spamcop.net",
+		Codes: map[string]string{
+			"127.0.0.2": "Listed on SpamCop Blocking List",
+		},
+	},
+	{
+		Name: "Mailspike",
+		Zone: "bl.mailspike.net",
+		Codes: map[string]string{
+			"127.0.0.2":  "Mailspike blocklist",
+			"127.0.0.10": "Worst possible reputation",
+			"127.0.0.11": "Very bad reputation",
+			"127.0.0.12": "Bad reputation",
+			"127.0.0.13": "Suspicious reputation",
+			"127.0.0.14": "Neutral, probably spam",
+		},
+	},
+	{
+		Name: "PSBL",
+		Zone: "psbl.surriel.com",
+		Codes: map[string]string{
+			"127.0.0.2": "Listed on Passive Spam Block List",
+		},
+	},
+	{
+		Name: "UCEPROTECT Level 1",
+		Zone: "dnsbl-1.uceprotect.net",
+		Codes: map[string]string{
+			"127.0.0.2": "Single IP listed on UCEPROTECT Level 1",
+		},
+	},
+}
+
+// getDNSBLZones returns the configured DNSBL zones. DNSBL_ZONES takes a comma separated
+// list of "Name=zone" or bare zones; return codes of known zones are still decoded.
+func getDNSBLZones() []DNSBLZone {
+	configured := getenvDefault("DNSBL_ZONES", "")
+	if configured == "" {
+		return defaultDNSBLZones
+	}
+
+	var zones []DNSBLZone
+	for _, entry := range strings.Split(configured, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, zone := entry, entry
+		if parts := strings.SplitN(entry, "=", 2); len(parts) == 2 {
+			name, zone = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		}
+		z := DNSBLZone{Name: name, Zone: strings.ToLower(zone)}
+		for _, known := range defaultDNSBLZones {
+			if known.Zone == z.Zone {
+				z.Codes = known.Codes
+			}
+		}
+		zones = append(zones, z)
+	}
+	return zones
+}
+
+// reverseIPForDNSBL returns the reversed-octet (IPv4) or reversed-nibble (IPv6) query label
+func reverseIPForDNSBL(ip net.IP) string {
+	if v4 := ip.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d", v4[3], v4[2], v4[1], v4[0])
+	}
+	v6 := ip.To16()
+	nibbles := make([]string, 0, 32)
+	for i := len(v6) - 1; i >= 0; i-- {
+		nibbles = append(nibbles, fmt.Sprintf("%x", v6[i]&0x0f), fmt.Sprintf("%x", v6[i]>>4))
+	}
+	return strings.Join(nibbles, ".")
+}
+
+// queryDNSBL checks a single IP against a single DNSBL zone
+func queryDNSBL(ip net.IP, zl DNSBLZone) DNSBLResult {
+	result := DNSBLResult{Name: zl.Name, Zone: zl.Zone}
+	query := reverseIPForDNSBL(ip) + "." + zl.Zone
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, query)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return result
+		}
+		result.Error = fmt.Sprintf("Lookup failed: %v", err)
+		return result
+	}
+
+	for _, addr := range addrs {
+		if !strings.HasPrefix(addr, "127.") {
+			continue
+		}
+		result.ReturnCodes = append(result.ReturnCodes, addr)
+		reason, ok := zl.Codes[addr]
+		if !ok {
+			reason = "Listed"
+		}
+		if strings.HasPrefix(reason, "Error:") {
+			// Blocklist refused to answer, this is not a listing
+			result.Error = reason
+			continue
+		}
+		result.Listed = true
+		result.Reasons = append(result.Reasons, reason)
+	}
+
+	if result.Listed {
+		if txts, err := net.DefaultResolver.LookupTXT(ctx, query); err == nil {
+			result.Text = strings.Join(txts, " ")
+		}
+	}
+
+	return result
+}
+
+// CheckIPReputation queries the configured DNSBLs for an IP concurrently
+func (nc *NetChecker) CheckIPReputation(ipAddr string) IPReputationInfo {
+	zones := getDNSBLZones()
+	info := IPReputationInfo{Checked: len(zones), Results: make([]DNSBLResult, len(zones))}
+
+	ip := net.ParseIP(ipAddr)
+	if ip == nil {
+		return info
+	}
+
+	var wg sync.WaitGroup
+	for i, z := range zones {
+		wg.Add(1)
+		go func(i int, z DNSBLZone) {
+			defer wg.Done()
+			info.Results[i] = queryDNSBL(ip, z)
+		}(i, z)
+	}
+	wg.Wait()
+
+	for _, r := range info.Results {
+		if r.Listed {
+			info.ListedCount++
+		}
+	}
+	return info
+}
+
 // CheckIP checks IP address information (accepts both IP addresses and domain names)
 func (nc *NetChecker) CheckIP(input string) IPInfo {
 	info := IPInfo{Input: input}
@@ -868,15 +1193,13 @@ func (nc *NetChecker) CheckIP(input string) IPInfo {
 		info.IsDomain = false
 		info.IP = cleanInput
 
-		// Try to establish connection to check if IP is reachable (with timeout)
-		dialer := net.Dialer{Timeout: 2 * time.Second}
-		conn, err := dialer.Dial("tcp", cleanInput+":80")
-		if err == nil {
-			conn.Close()
-		}
-
 		// Get geolocation information from MaxMind
 		info.Country, info.Region, info.City, info.ISP, info.Organization, info.Timezone = lookupGeoIP(cleanInput)
+		info.ASN, _ = lookupASN(cleanInput)
+
+		// Check the IP against DNS-based blocklists
+		reputation := nc.CheckIPReputation(cleanInput)
+		info.Reputation = &reputation
 
 		return info
 	} else {
@@ -900,15 +1223,13 @@ func (nc *NetChecker) CheckIP(input string) IPInfo {
 			// Use the first resolved IP
 			info.IP = info.ResolvedIPs[0]
 
-			// Try to establish connection (with timeout)
-			dialer := net.Dialer{Timeout: 2 * time.Second}
-			conn, err := dialer.Dial("tcp", info.IP+":80")
-			if err == nil {
-				conn.Close()
-			}
-
 			// Get geolocation information from MaxMind
 			info.Country, info.Region, info.City, info.ISP, info.Organization, info.Timezone = lookupGeoIP(info.IP)
+			info.ASN, _ = lookupASN(info.IP)
+
+			// Check the IP against DNS-based blocklists
+			reputation := nc.CheckIPReputation(info.IP)
+			info.Reputation = &reputation
 		} else {
 			info.Error = "No IP addresses found for domain"
 		}
@@ -917,1261 +1238,10349 @@ func (nc *NetChecker) CheckIP(input string) IPInfo {
 	}
 }
 
-// CheckWebSettings checks web server settings and headers
-func (nc *NetChecker) CheckWebSettings(domain string) WebSettingsInfo {
-	info := WebSettingsInfo{Domain: domain}
+// -----------------------------
+// Request customization
+// -----------------------------
 
-	// Ensure domain has protocol
-	if !strings.HasPrefix(domain, "https://") && !strings.HasPrefix(domain, "http://") {
-		domain = "https://" + domain
-	}
+// Request customization limits
+const (
+	maxRequestBodyBytes = 64 << 10
+	maxRequestHeaders   = 20
+)
 
-	start := time.Now()
-	resp, err := nc.httpClient.Get(domain)
-	info.ResponseTime = time.Since(start).Milliseconds()
+// userAgentPresets maps preset names to the User-Agent strings they send
+var userAgentPresets = map[string]string{
+	"desktop":   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"mobile":    "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+	"googlebot": "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+	"curl":      "curl/8.7.1",
+}
 
+// allowedRequestMethods are the methods web checks may send; TRACE and CONNECT are excluded
+var allowedRequestMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// WebRequestOptions describes the request a web check sends
+type WebRequestOptions struct {
+	Method          string            `json:"method"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            string            `json:"body,omitempty"`
+	UserAgent       string            `json:"user_agent,omitempty"`
+	FollowRedirects bool              `json:"follow_redirects"`
+}
+
+// newRequest builds the HTTP request described by the options
+func (o WebRequestOptions) newRequest(target string) (*http.Request, error) {
+	var body io.Reader
+	if o.Body != "" {
+		body = strings.NewReader(o.Body)
+	}
+	req, err := http.NewRequest(o.Method, target, body)
 	if err != nil {
-		info.Error = fmt.Sprintf("Failed to connect: %v", err)
-		return info
+		return nil, err
 	}
-	defer resp.Body.Close()
+	for name, value := range o.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	if o.UserAgent != "" {
+		req.Header.Set("User-Agent", o.UserAgent)
+	}
+	return req, nil
+}
 
-	info.StatusCode = resp.StatusCode
-	info.Server = resp.Header.Get("Server")
-	info.ContentType = resp.Header.Get("Content-Type")
-	info.LastModified = resp.Header.Get("Last-Modified")
-	info.ETag = resp.Header.Get("ETag")
-	info.ContentLength = resp.ContentLength
+// parseWebRequestOptions reads method, header, body, user_agent and follow_redirects
+// query parameters. A user_agent that is not a preset name is sent verbatim.
+func parseWebRequestOptions(c *gin.Context, defaultMethod string) (WebRequestOptions, error) {
+	opts := WebRequestOptions{
+		Method:          strings.ToUpper(c.DefaultQuery("method", defaultMethod)),
+		Body:            c.Query("body"),
+		FollowRedirects: c.DefaultQuery("follow_redirects", "true") != "false",
+	}
 
-	// Check for redirects
-	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
-		info.RedirectURL = resp.Header.Get("Location")
+	if !contains(allowedRequestMethods, opts.Method) {
+		return opts, fmt.Errorf("method must be one of %s", strings.Join(allowedRequestMethods, ", "))
+	}
+	if len(opts.Body) > maxRequestBodyBytes {
+		return opts, fmt.Errorf("body exceeds %d bytes", maxRequestBodyBytes)
 	}
 
-	// Parse HSTS header
-	hstsHeader := resp.Header.Get("Strict-Transport-Security")
-	info.HSTS = parseHSTSHeader(hstsHeader)
+	headers := c.QueryArray("header")
+	if len(headers) > maxRequestHeaders {
+		return opts, fmt.Errorf("at most %d headers may be specified", maxRequestHeaders)
+	}
+	for _, h := range headers {
+		parts := strings.SplitN(h, ":", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" {
+			return opts, fmt.Errorf("header %q must be in Name: value form", h)
+		}
+		if opts.Headers == nil {
+			opts.Headers = make(map[string]string)
+		}
+		opts.Headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(parts[1])
+	}
 
-	// Collect all headers
-	info.Headers = make(map[string]string)
-	for key, values := range resp.Header {
-		info.Headers[key] = strings.Join(values, ", ")
+	if ua := c.Query("user_agent"); ua != "" {
+		if preset, ok := userAgentPresets[strings.ToLower(ua)]; ok {
+			opts.UserAgent = preset
+		} else {
+			opts.UserAgent = ua
+		}
 	}
 
-	return info
+	return opts, nil
 }
 
-// CheckEmailConfig checks email authentication configuration (SPF, DKIM, DMARC, BIMI)
-func (nc *NetChecker) CheckEmailConfig(domain string) EmailConfigInfo {
-	info := EmailConfigInfo{Domain: domain}
+// readOnly reports whether the request method cannot change state on the target
+func (o WebRequestOptions) readOnly() bool {
+	return o.Method == "GET" || o.Method == "HEAD"
+}
 
-	// Clean domain
-	cleanDomain := strings.TrimPrefix(domain, "https://")
-	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
-	cleanDomain = strings.Split(cleanDomain, "/")[0]
+// checkTarget rejects hosts that resolve to non-public addresses. Methods that can
+// change state must reach a globally routable address even with ALLOW_PRIVATE_TARGETS.
+func (o WebRequestOptions) checkTarget(targetURL string) error {
+	u, err := url.Parse(targetURL)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("invalid URL: %s", targetURL)
+	}
+	ip, err := resolveTarget(u.Hostname())
+	if err != nil {
+		return err
+	}
+	if !o.readOnly() && !isRoutableIP(ip) {
+		return fmt.Errorf("%s requests may only be sent to public addresses", o.Method)
+	}
+	return nil
+}
 
-	// Check SPF
-	info.SPF = nc.CheckSPF(cleanDomain)
+// cacheKey returns a stable cache key component for the options
+func (o WebRequestOptions) cacheKey() string {
+	b, _ := json.Marshal(o)
+	return string(b)
+}
 
-	// Check DKIM
-	info.DKIM = nc.CheckDKIM(cleanDomain)
+// -----------------------------
+// Redirect chain tracing
+// -----------------------------
 
-	// Check DMARC
-	info.DMARC = nc.CheckDMARC(cleanDomain)
+// Redirect chain limits
+const (
+	maxRedirectHops   = 10
+	longRedirectChain = 3
+)
+
+// RedirectHop represents a single request in a redirect chain
+type RedirectHop struct {
+	URL         string         `json:"url"`
+	StatusCode  int            `json:"status_code"`
+	Location    string         `json:"location,omitempty"`
+	HSTS        HSTSInfo       `json:"hsts"`
+	TimeMs      int64          `json:"time_ms"`
+	Certificate *SSLInfo       `json:"certificate,omitempty"`
+	Timing      *RequestTiming `json:"timing,omitempty"`
+
+	header http.Header
+}
 
-	// Check BIMI
-	info.BIMI = nc.CheckBIMI(cleanDomain)
+// RedirectChainInfo represents every hop between the requested and the final URL
+type RedirectChainInfo struct {
+	Hops      []RedirectHop `json:"hops"`
+	FinalURL  string        `json:"final_url"`
+	Count     int           `json:"count"`
+	Loop      bool          `json:"loop"`
+	Downgrade bool          `json:"https_downgrade"`
+	TooLong   bool          `json:"too_long"`
+	Issues    []string      `json:"issues,omitempty"`
+
+	tracer *requestTracer // final hop, completed by finishTiming
+}
 
+// certificateInfo summarizes a peer certificate as SSLInfo
+func certificateInfo(domain string, cert *x509.Certificate) SSLInfo {
+	info := SSLInfo{
+		Domain:          domain,
+		Valid:           time.Now().Before(cert.NotAfter) && time.Now().After(cert.NotBefore),
+		Issuer:          cert.Issuer.String(),
+		Subject:         cert.Subject.String(),
+		NotBefore:       cert.NotBefore,
+		NotAfter:        cert.NotAfter,
+		DaysUntilExpiry: int(time.Until(cert.NotAfter).Hours() / 24),
+		SerialNumber:    cert.SerialNumber.String(),
+		SignatureAlg:    cert.SignatureAlgorithm.String(),
+		PublicKeyAlg:    cert.PublicKeyAlgorithm.String(),
+	}
+	if pubKey, ok := cert.PublicKey.(*rsa.PublicKey); ok {
+		info.KeySize = pubKey.N.BitLen()
+	}
 	return info
 }
 
-// CheckSPF checks SPF (Sender Policy Framework) record
-func (nc *NetChecker) CheckSPF(domain string) SPFInfo {
-	info := SPFInfo{}
+// followRedirects performs req and, if follow is set, follows redirects manually,
+// recording every hop. The returned response is the final hop; its body must be
+// closed by the caller. Every hop is checked against the SSRF guard when it connects,
+// and chains started with a method that can change state only reach routable addresses.
+func (nc *NetChecker) followRedirects(req *http.Request, follow bool) (*http.Response, RedirectChainInfo, error) {
+	var chain RedirectChainInfo
 
-	// SPF records are TXT records for the domain
-	txtRecords, err := net.LookupTXT(domain)
-	if err != nil {
-		info.Error = fmt.Sprintf("Failed to lookup TXT records: %v", err)
-		return info
+	allowed := isPublicIP
+	if req.Method != "GET" && req.Method != "HEAD" {
+		allowed = isRoutableIP
+	}
+	client := nc.guardedHTTPClient(allowed)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
 	}
 
-	// Look for SPF record
-	for _, txt := range txtRecords {
-		if strings.HasPrefix(strings.ToLower(txt), "v=spf1") {
-			info.Configured = true
-			info.Record = txt
-			info.Valid = true
-			info.Details = "SPF record found and configured"
-			break
+	var resp *http.Response
+	seen := make(map[string]bool)
+	for {
+		hop := RedirectHop{URL: req.URL.String()}
+		if seen[hop.URL] {
+			chain.Loop = true
+			chain.Issues = append(chain.Issues, fmt.Sprintf("Redirect loop detected at %s", hop.URL))
+			return nil, chain, fmt.Errorf("redirect loop detected at %s", hop.URL)
 		}
-	}
+		seen[hop.URL] = true
 
-	if !info.Configured {
-		info.Details = "No SPF record found"
-	}
+		var tracer *requestTracer
+		req, tracer = newRequestTracer(req)
 
-	return info
-}
+		start := time.Now()
+		var err error
+		resp, err = client.Do(req)
+		hop.TimeMs = time.Since(start).Milliseconds()
+		if err != nil {
+			return nil, chain, err
+		}
+		chain.tracer = tracer
+
+		hop.StatusCode = resp.StatusCode
+		hop.header = resp.Header
+		hop.HSTS = parseHSTSHeader(resp.Header.Get("Strict-Transport-Security"))
+		if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+			cert := certificateInfo(req.URL.Hostname(), resp.TLS.PeerCertificates[0])
+			hop.Certificate = &cert
+		}
 
-// CheckDKIM checks DKIM (DomainKeys Identified Mail) records
-func (nc *NetChecker) CheckDKIM(domain string) DKIMInfo {
-	info := DKIMInfo{}
+		next, locErr := resp.Location()
+		isRedirect := resp.StatusCode >= 300 && resp.StatusCode < 400 && locErr == nil
+		if isRedirect {
+			hop.Location = next.String()
+		}
+		chain.Hops = append(chain.Hops, hop)
 
-	// Common DKIM selectors to check
-	commonSelectors := []string{"default", "dkim", "key1", "selector1", "s1", "s2"}
+		if !isRedirect || !follow {
+			chain.FinalURL = hop.URL
+			break
+		}
+		resp.Body.Close()
+		timing := tracer.timing(time.Now(), 0)
+		chain.Hops[len(chain.Hops)-1].Timing = &timing
+
+		chain.Count++
+		if req.URL.Scheme == "https" && next.Scheme == "http" {
+			chain.Downgrade = true
+			chain.Issues = append(chain.Issues, fmt.Sprintf("HTTPS to HTTP downgrade: %s -> %s", hop.URL, hop.Location))
+		}
+		if chain.Count >= maxRedirectHops {
+			chain.TooLong = true
+			chain.Issues = append(chain.Issues, fmt.Sprintf("Stopped after %d redirects", maxRedirectHops))
+			return nil, chain, fmt.Errorf("stopped after %d redirects", maxRedirectHops)
+		}
 
-	// Check for DKIM records in common locations
-	found := false
-	for _, selector := range commonSelectors {
-		dkimDomain := selector + "._domainkey." + domain
-		txtRecords, err := net.LookupTXT(dkimDomain)
-		if err == nil {
-			for _, txt := range txtRecords {
-				if strings.HasPrefix(strings.ToLower(txt), "v=dkim1") {
-					info.Selectors = append(info.Selectors, selector)
-					found = true
+		// 301/302/303 switch to GET as browsers do; 307/308 keep the method
+		method := req.Method
+		var body io.ReadCloser
+		if resp.StatusCode == http.StatusTemporaryRedirect || resp.StatusCode == http.StatusPermanentRedirect {
+			if req.GetBody != nil {
+				if body, err = req.GetBody(); err != nil {
+					return nil, chain, err
 				}
 			}
+		} else if method != "HEAD" {
+			method = "GET"
+		}
+		nextReq, err := http.NewRequestWithContext(req.Context(), method, next.String(), body)
+		if err != nil {
+			return nil, chain, err
+		}
+		for key, values := range req.Header {
+			nextReq.Header[key] = values
 		}
+		if body != nil {
+			nextReq.ContentLength = req.ContentLength
+			nextReq.GetBody = req.GetBody
+		} else {
+			nextReq.Header.Del("Content-Type")
+			nextReq.Header.Del("Content-Length")
+		}
+		// Like net/http, credentials only follow redirects to the same host or a subdomain
+		if !redirectKeepsCredentials(req.URL, next) {
+			nextReq.Header.Del("Authorization")
+			nextReq.Header.Del("Cookie")
+			nextReq.Header.Del("Proxy-Authorization")
+			nextReq.Header.Del("Www-Authenticate")
+		}
+		req = nextReq
 	}
 
-	if found {
-		info.Configured = true
-		info.Valid = true
-		info.Details = fmt.Sprintf("DKIM records found for selectors: %v", info.Selectors)
-	} else {
-		info.Details = "No DKIM records found for common selectors"
+	if chain.Count > longRedirectChain {
+		chain.TooLong = true
+		chain.Issues = append(chain.Issues, fmt.Sprintf("Long redirect chain: %d redirects", chain.Count))
 	}
 
-	return info
+	return resp, chain, nil
 }
 
-// CheckDMARC checks DMARC (Domain-based Message Authentication) record
-func (nc *NetChecker) CheckDMARC(domain string) DMARCInfo {
-	info := DMARCInfo{}
+// redirectKeepsCredentials reports whether sensitive headers may be sent to a redirect
+// target: the host must be the same as the original or one of its subdomains
+func redirectKeepsCredentials(from, to *url.URL) bool {
+	src := strings.ToLower(from.Hostname())
+	dst := strings.ToLower(to.Hostname())
+	return dst == src || strings.HasSuffix(dst, "."+src)
+}
 
-	// DMARC record is in _dmarc subdomain
-	dmarcDomain := "_dmarc." + domain
-	txtRecords, err := net.LookupTXT(dmarcDomain)
-	if err != nil {
-		info.Error = fmt.Sprintf("Failed to lookup DMARC record: %v", err)
-		info.Details = "No DMARC record found"
-		return info
-	}
+// -----------------------------
+// Request timing breakdown
+// -----------------------------
 
-	// Look for DMARC record
-	for _, txt := range txtRecords {
-		if strings.HasPrefix(strings.ToLower(txt), "v=dmarc1") {
-			info.Configured = true
-			info.Record = txt
-			info.Valid = true
+// maxTimedBodyBytes caps how much of a response body is read when timing the transfer
+const maxTimedBodyBytes = 10 << 20
+
+// RequestTiming represents the waterfall breakdown of a single HTTP request
+type RequestTiming struct {
+	DNSLookupMs        float64 `json:"dns_lookup_ms"`
+	TCPConnectMs       float64 `json:"tcp_connect_ms"`
+	TLSHandshakeMs     float64 `json:"tls_handshake_ms"`
+	ServerProcessingMs float64 `json:"server_processing_ms"`
+	TimeToFirstByteMs  float64 `json:"time_to_first_byte_ms"`
+	ContentTransferMs  float64 `json:"content_transfer_ms"`
+	TotalMs            float64 `json:"total_ms"`
+	BytesRead          int64   `json:"bytes_read,omitempty"`
+	RemoteAddr         string  `json:"remote_addr,omitempty"`
+	ConnectionReused   bool    `json:"connection_reused"`
+}
 
-			// Parse policy
-			if strings.Contains(txt, "p=none") {
-				info.Policy = "none"
-			} else if strings.Contains(txt, "p=quarantine") {
-				info.Policy = "quarantine"
-			} else if strings.Contains(txt, "p=reject") {
-				info.Policy = "reject"
-			}
+// requestTracer collects httptrace events for one request
+type requestTracer struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	remoteAddr   string
+	reused       bool
+}
 
-			info.Details = fmt.Sprintf("DMARC record found with policy: %s", info.Policy)
-			break
-		}
+// newRequestTracer attaches a tracer to req and returns both
+func newRequestTracer(req *http.Request) (*http.Request, *requestTracer) {
+	t := &requestTracer{start: time.Now()}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.set(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.set(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			// Keep the first attempt when dialing several addresses
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err == nil {
+				t.set(&t.connectDone)
+			}
+		},
+		TLSHandshakeStart: func() { t.set(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.set(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+			t.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.set(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.set(&t.firstByte) },
 	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), t
+}
 
-	if !info.Configured {
-		info.Details = "No DMARC record found"
-	}
+func (t *requestTracer) set(field *time.Time) {
+	t.mu.Lock()
+	*field = time.Now()
+	t.mu.Unlock()
+}
 
-	return info
+// span returns the duration between two events in milliseconds, or 0 if either is missing
+func span(from, to time.Time) float64 {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+	return roundMs(to.Sub(from))
 }
 
-// CheckBIMI checks BIMI (Brand Indicators for Message Identification) record
-func (nc *NetChecker) CheckBIMI(domain string) BIMIInfo {
-	info := BIMIInfo{}
+// timing builds the breakdown; bodyDone marks when the body was fully read
+func (t *requestTracer) timing(bodyDone time.Time, bytesRead int64) RequestTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timing := RequestTiming{
+		DNSLookupMs:        span(t.dnsStart, t.dnsDone),
+		TCPConnectMs:       span(t.connectStart, t.connectDone),
+		TLSHandshakeMs:     span(t.tlsStart, t.tlsDone),
+		ServerProcessingMs: span(t.wroteRequest, t.firstByte),
+		TimeToFirstByteMs:  span(t.start, t.firstByte),
+		ContentTransferMs:  span(t.firstByte, bodyDone),
+		TotalMs:            span(t.start, bodyDone),
+		BytesRead:          bytesRead,
+		RemoteAddr:         t.remoteAddr,
+		ConnectionReused:   t.reused,
+	}
+	if timing.TotalMs == 0 {
+		timing.TotalMs = timing.TimeToFirstByteMs
+	}
+	return timing
+}
 
-	// BIMI record is in default._bimi subdomain
-	bimiDomain := "default._bimi." + domain
-	txtRecords, err := net.LookupTXT(bimiDomain)
-	if err != nil {
-		info.Details = "No BIMI record found"
-		return info
+// finishTiming reads the final response body and completes the last hop's timing
+func (chain *RedirectChainInfo) finishTiming(body io.Reader) RequestTiming {
+	n, _ := io.Copy(io.Discard, io.LimitReader(body, maxTimedBodyBytes))
+	if chain.tracer == nil || len(chain.Hops) == 0 {
+		return RequestTiming{}
 	}
+	timing := chain.tracer.timing(time.Now(), n)
+	chain.Hops[len(chain.Hops)-1].Timing = &timing
+	return timing
+}
 
-	// Look for BIMI record
-	for _, txt := range txtRecords {
-		if strings.HasPrefix(strings.ToLower(txt), "v=bimi1") {
-			info.Configured = true
-			info.Record = txt
-			info.Valid = true
+// -----------------------------
+// Cookie security audit
+// -----------------------------
 
-			// Parse logo URL
-			if strings.Contains(txt, "l=") {
-				parts := strings.Split(txt, "l=")
-				if len(parts) > 1 {
-					info.LogoURL = strings.TrimSpace(strings.Split(parts[1], ";")[0])
-				}
-			}
+// Cookie audit limits, per RFC 6265bis
+const (
+	maxCookieSize   = 4096
+	maxCookieMaxAge = 400 * 24 * time.Hour
+)
 
-			info.Details = "BIMI record found"
-			if info.LogoURL != "" {
-				info.Details = fmt.Sprintf("BIMI record found with logo: %s", info.LogoURL)
-			}
-			break
+// sessionCookieRegex matches cookie names that usually carry authentication state
+var sessionCookieRegex = regexp.MustCompile(`(?i)(sess|sid|auth|token|jwt|login|remember|csrf|xsrf)`)
+
+// CookieInfo represents the security attributes of a single Set-Cookie header
+type CookieInfo struct {
+	Name       string   `json:"name"`
+	SetBy      string   `json:"set_by"`
+	Secure     bool     `json:"secure"`
+	HttpOnly   bool     `json:"http_only"`
+	SameSite   string   `json:"same_site,omitempty"`
+	Domain     string   `json:"domain,omitempty"`
+	Path       string   `json:"path,omitempty"`
+	Expires    string   `json:"expires,omitempty"`
+	MaxAge     int      `json:"max_age,omitempty"`
+	Persistent bool     `json:"persistent"`
+	Size       int      `json:"size"`
+	Prefix     string   `json:"prefix,omitempty"`
+	Issues     []string `json:"issues,omitempty"`
+
+	value string // kept for fingerprinting, never reported
+}
+
+// CookieAuditInfo represents the cookie security audit of a response chain
+type CookieAuditInfo struct {
+	Count      int          `json:"count"`
+	IssueCount int          `json:"issue_count"`
+	Cookies    []CookieInfo `json:"cookies"`
+}
+
+// auditCookie parses one Set-Cookie header and checks it against browser rules
+func auditCookie(raw string, pageURL *url.URL) CookieInfo {
+	info := CookieInfo{SetBy: pageURL.String(), Size: len(raw)}
+
+	cookie, err := http.ParseSetCookie(raw)
+	if err != nil {
+		info.Name = strings.SplitN(raw, "=", 2)[0]
+		info.Issues = append(info.Issues, fmt.Sprintf("Malformed Set-Cookie header: %v", err))
+		return info
+	}
+
+	isHTTPS := pageURL.Scheme == "https"
+	info.Name = cookie.Name
+	info.Secure = cookie.Secure
+	info.HttpOnly = cookie.HttpOnly
+	info.Domain = cookie.Domain
+	info.Path = cookie.Path
+	info.MaxAge = cookie.MaxAge
+	info.Size = len(cookie.Name) + len(cookie.Value)
+	info.value = cookie.Value
+	if !cookie.Expires.IsZero() {
+		info.Expires = cookie.Expires.UTC().Format(time.RFC3339)
+	}
+	info.Persistent = !cookie.Expires.IsZero() || cookie.MaxAge > 0
+
+	switch cookie.SameSite {
+	case http.SameSiteLaxMode:
+		info.SameSite = "Lax"
+	case http.SameSiteStrictMode:
+		info.SameSite = "Strict"
+	case http.SameSiteNoneMode:
+		info.SameSite = "None"
+	case http.SameSiteDefaultMode:
+		info.SameSite = "Invalid"
+	}
+
+	// Prefix rules
+	switch {
+	case strings.HasPrefix(cookie.Name, "__Host-"):
+		info.Prefix = "__Host-"
+		if !cookie.Secure || !isHTTPS {
+			info.Issues = append(info.Issues, "__Host- cookie must be set with Secure from an HTTPS origin")
+		}
+		if cookie.Domain != "" {
+			info.Issues = append(info.Issues, "__Host- cookie must not have a Domain attribute")
+		}
+		if cookie.Path != "/" {
+			info.Issues = append(info.Issues, "__Host- cookie must have Path=/")
+		}
+	case strings.HasPrefix(cookie.Name, "__Secure-"):
+		info.Prefix = "__Secure-"
+		if !cookie.Secure || !isHTTPS {
+			info.Issues = append(info.Issues, "__Secure- cookie must be set with Secure from an HTTPS origin")
 		}
 	}
 
-	if !info.Configured {
-		info.Details = "No BIMI record found"
+	// Transport and script access
+	looksLikeSession := sessionCookieRegex.MatchString(cookie.Name)
+	if isHTTPS && !cookie.Secure {
+		if looksLikeSession {
+			info.Issues = append(info.Issues, "Session cookie without Secure on an HTTPS site can leak over plain HTTP")
+		} else {
+			info.Issues = append(info.Issues, "Missing Secure attribute on an HTTPS site")
+		}
+	}
+	if looksLikeSession && !cookie.HttpOnly {
+		info.Issues = append(info.Issues, "Session cookie without HttpOnly is readable by JavaScript")
+	}
+
+	// SameSite
+	switch info.SameSite {
+	case "":
+		info.Issues = append(info.Issues, "No SameSite attribute; browsers default to Lax but behaviour differs")
+	case "Invalid":
+		info.Issues = append(info.Issues, "Invalid SameSite value")
+	case "None":
+		if !cookie.Secure {
+			info.Issues = append(info.Issues, "SameSite=None without Secure is rejected by browsers")
+		}
+	}
+
+	// Scope
+	if cookie.Domain != "" {
+		domain := strings.TrimPrefix(strings.ToLower(cookie.Domain), ".")
+		host := strings.ToLower(pageURL.Hostname())
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			info.Issues = append(info.Issues, fmt.Sprintf("Domain %s does not match host %s; browsers reject the cookie", cookie.Domain, host))
+		} else {
+			info.Issues = append(info.Issues, fmt.Sprintf("Domain=%s shares the cookie with all subdomains", cookie.Domain))
+		}
+	}
+
+	// Size and lifetime
+	if info.Size > maxCookieSize {
+		info.Issues = append(info.Issues, fmt.Sprintf("Cookie is %d bytes, browsers drop cookies over %d bytes", info.Size, maxCookieSize))
+	}
+	if cookie.MaxAge > 0 && time.Duration(cookie.MaxAge)*time.Second > maxCookieMaxAge ||
+		!cookie.Expires.IsZero() && time.Until(cookie.Expires) > maxCookieMaxAge {
+		info.Issues = append(info.Issues, "Lifetime exceeds 400 days; browsers cap it")
 	}
 
 	return info
 }
 
-// CombinedResult holds both SSL and WebSettings info from a single connection
-type CombinedResult struct {
-	SSL         SSLInfo
-	WebSettings WebSettingsInfo
+// AuditCookies audits every Set-Cookie header seen along a redirect chain
+func AuditCookies(chain RedirectChainInfo) CookieAuditInfo {
+	audit := CookieAuditInfo{Cookies: []CookieInfo{}}
+	for _, hop := range chain.Hops {
+		pageURL, err := url.Parse(hop.URL)
+		if err != nil {
+			continue
+		}
+		for _, raw := range hop.header.Values("Set-Cookie") {
+			cookie := auditCookie(raw, pageURL)
+			audit.IssueCount += len(cookie.Issues)
+			audit.Cookies = append(audit.Cookies, cookie)
+		}
+	}
+	audit.Count = len(audit.Cookies)
+	return audit
 }
 
-// CheckSSLAndWebSettings performs both SSL and WebSettings checks using a single HTTP request
-func (nc *NetChecker) CheckSSLAndWebSettings(domain string) CombinedResult {
-	var result CombinedResult
+// cookieValues maps the names of audited cookies to their values
+func cookieValues(audit CookieAuditInfo) map[string]string {
+	values := make(map[string]string, len(audit.Cookies))
+	for _, cookie := range audit.Cookies {
+		values[cookie.Name] = cookie.value
+	}
+	return values
+}
 
-	// Clean domain for HTTP request
-	fullURL := domain
+// flattenHeaders joins repeated header values for display. Set-Cookie values are
+// newline separated since their Expires dates contain commas.
+func flattenHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string)
+	for key, values := range header {
+		sep := ", "
+		if key == "Set-Cookie" {
+			sep = "\n"
+		}
+		headers[key] = strings.Join(values, sep)
+	}
+	return headers
+}
+
+// defaultWebRequest is the request CheckWebSettings sends when not customized
+var defaultWebRequest = WebRequestOptions{Method: "GET", FollowRedirects: true}
+
+// CheckWebSettings checks web server settings and headers
+func (nc *NetChecker) CheckWebSettings(domain string, opts WebRequestOptions) WebSettingsInfo {
+	info := WebSettingsInfo{Domain: domain, Request: &opts}
+
+	// Ensure domain has protocol
 	if !strings.HasPrefix(domain, "https://") && !strings.HasPrefix(domain, "http://") {
-		fullURL = "https://" + domain
+		domain = "https://" + domain
 	}
 
-	cleanDomain := strings.TrimPrefix(fullURL, "https://")
-	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
-	cleanDomain = strings.Split(cleanDomain, "/")[0]
+	if err := opts.checkTarget(domain); err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	req, err := opts.newRequest(domain)
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to create request: %v", err)
+		return info
+	}
 
-	// Make HTTP request and get certificate from TLS connection
 	start := time.Now()
-	req, err := http.NewRequest("HEAD", fullURL, nil)
+	resp, chain, err := nc.followRedirects(req, opts.FollowRedirects)
+	info.ResponseTime = time.Since(start).Milliseconds()
+	info.Redirects = chain
+
+	// Record where the requested URL redirects to
+	if len(chain.Hops) > 0 && chain.Hops[0].Location != "" {
+		info.RedirectURL = chain.Hops[0].Location
+	}
+
 	if err != nil {
-		result.WebSettings.Error = fmt.Sprintf("Failed to create request: %v", err)
-		result.SSL.Error = result.WebSettings.Error
-		return result
+		info.Error = fmt.Sprintf("Failed to connect: %v", err)
+		return info
 	}
+	defer resp.Body.Close()
 
-	resp, err := nc.httpClient.Do(req)
-	result.WebSettings.ResponseTime = time.Since(start).Milliseconds()
-	result.WebSettings.Domain = cleanDomain
+	info.StatusCode = resp.StatusCode
+	info.Server = resp.Header.Get("Server")
+	info.ContentType = resp.Header.Get("Content-Type")
+	info.LastModified = resp.Header.Get("Last-Modified")
+	info.ETag = resp.Header.Get("ETag")
+	info.ContentLength = resp.ContentLength
+
+	// Parse HSTS header
+	hstsHeader := resp.Header.Get("Strict-Transport-Security")
+	info.HSTS = parseHSTSHeader(hstsHeader)
+
+	// Grade security headers of the final response
+	info.SecurityHeaders = AnalyzeSecurityHeaders(resp.Header, resp.Request.URL.Scheme == "https")
+
+	// Collect all headers
+	info.Headers = flattenHeaders(resp.Header)
+
+	// Audit cookies set anywhere along the redirect chain
+	info.Cookies = AuditCookies(chain)
+
+	// Read the body to complete the timing waterfall, keeping the HTML for fingerprinting
+	var html bytes.Buffer
+	info.Timing = chain.finishTiming(io.TeeReader(resp.Body, &html))
+	info.Technologies = DetectTechnologies(resp.Header, cookieValues(info.Cookies), html.String())
+
+	return info
+}
+
+// CheckEmailConfig checks email authentication configuration (SPF, DKIM, DMARC, BIMI).
+// With probeMX the MX hosts are also probed on port 25 to verify their MTA-STS
+// certificates, which can take tens of seconds.
+func (nc *NetChecker) CheckEmailConfig(domain string, probeMX bool) EmailConfigInfo {
+	info := nc.checkEmailConfig(domain)
+	if probeMX && len(info.MTASTS.MXHosts) > 0 {
+		applyMTASTSProbes(&info.MTASTS, nc.CheckSMTP(cleanEmailDomain(domain), []int{25}))
+	} else {
+		skipMTASTSProbes(&info.MTASTS, "pass probe_mx=true to verify them over STARTTLS")
+	}
+	return info
+}
+
+// cleanEmailDomain strips a URL scheme and path from domain
+func cleanEmailDomain(domain string) string {
+	cleanDomain := strings.TrimPrefix(domain, "https://")
+	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
+	return strings.Split(cleanDomain, "/")[0]
+}
+
+// checkEmailConfig runs the DNS and HTTPS email checks; MTA-STS certificates are
+// left for the caller to verify or skip
+func (nc *NetChecker) checkEmailConfig(domain string) EmailConfigInfo {
+	info := EmailConfigInfo{Domain: domain}
+	cleanDomain := cleanEmailDomain(domain)
+
+	// Check SPF
+	info.SPF = nc.CheckSPF(cleanDomain)
+
+	// Check DKIM
+	info.DKIM = nc.CheckDKIM(cleanDomain)
 
+	// Check DMARC
+	info.DMARC = nc.CheckDMARC(cleanDomain)
+
+	// Check BIMI against the DMARC result above
+	info.BIMI = nc.checkBIMI(cleanDomain, &info.DMARC)
+
+	// Check MTA-STS and TLS reporting
+	info.MTASTS = nc.CheckMTASTS(cleanDomain)
+	info.TLSRPT = nc.CheckTLSRPT(cleanDomain)
+
+	return info
+}
+
+// CheckSPF checks SPF (Sender Policy Framework) record
+func (nc *NetChecker) CheckSPF(domain string) SPFInfo {
+	info := SPFInfo{}
+
+	// SPF records are TXT records for the domain
+	records, err := lookupSPFRecords(domain)
 	if err != nil {
-		result.WebSettings.Error = fmt.Sprintf("Failed to connect: %v", err)
-		result.SSL.Domain = cleanDomain
-		result.SSL.Error = result.WebSettings.Error
-		return result
+		info.Error = fmt.Sprintf("Failed to lookup TXT records: %v", err)
+		return info
+	}
+	if len(records) == 0 {
+		info.Details = "No SPF record found"
+		return info
 	}
-	defer resp.Body.Close()
 
-	// Extract WebSettings info
-	result.WebSettings.StatusCode = resp.StatusCode
-	result.WebSettings.Server = resp.Header.Get("Server")
-	result.WebSettings.ContentType = resp.Header.Get("Content-Type")
-	result.WebSettings.LastModified = resp.Header.Get("Last-Modified")
-	result.WebSettings.ETag = resp.Header.Get("ETag")
-	result.WebSettings.ContentLength = resp.ContentLength
+	info.Configured = true
+	info.Records = records
+	info.Record = records[0]
 
-	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
-		result.WebSettings.RedirectURL = resp.Header.Get("Location")
+	w := newSPFWalker()
+	if len(records) > 1 {
+		w.errorf("Multiple SPF records published; receivers return permerror")
 	}
+	info.Tree = w.walk(domain, records[0], true, true)
 
-	// Parse HSTS header
-	hstsHeader := resp.Header.Get("Strict-Transport-Security")
-	result.WebSettings.HSTS = parseHSTSHeader(hstsHeader)
+	info.DNSLookups = w.lookups
+	info.VoidLookups = w.voids
+	info.AllQualifier = w.allQualifier
+	info.IPv4 = w.flattened(w.ipv4)
+	info.IPv6 = w.flattened(w.ipv6)
+	info.Errors = w.errors
+	info.Warnings = w.warnings
+	info.Valid = len(w.errors) == 0
 
-	result.WebSettings.Headers = make(map[string]string)
-	for key, values := range resp.Header {
-		result.WebSettings.Headers[key] = strings.Join(values, ", ")
+	if info.Valid {
+		info.Details = fmt.Sprintf("SPF record valid with %d DNS lookups", info.DNSLookups)
+	} else {
+		info.Details = fmt.Sprintf("SPF record invalid: %s", w.errors[0])
 	}
 
-	// Extract SSL certificate from TLS connection state
-	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
-		cert := resp.TLS.PeerCertificates[0]
-		result.SSL.Domain = cleanDomain
-		result.SSL.Valid = true
-		result.SSL.Issuer = cert.Issuer.String()
-		result.SSL.Subject = cert.Subject.String()
-		result.SSL.NotBefore = cert.NotBefore
-		result.SSL.NotAfter = cert.NotAfter
-		result.SSL.DaysUntilExpiry = int(time.Until(cert.NotAfter).Hours() / 24)
-		result.SSL.SerialNumber = cert.SerialNumber.String()
-		result.SSL.SignatureAlg = cert.SignatureAlgorithm.String()
-		result.SSL.PublicKeyAlg = cert.PublicKeyAlgorithm.String()
+	return info
+}
 
-		switch pubKey := cert.PublicKey.(type) {
-		case *rsa.PublicKey:
-			result.SSL.KeySize = pubKey.N.BitLen()
+// -----------------------------
+// SPF record analysis (RFC 7208)
+// -----------------------------
+
+// SPF processing limits, RFC 7208 section 4.6.4
+const (
+	spfMaxDNSLookups  = 10
+	spfMaxVoidLookups = 2
+	spfMaxNames       = 10
+)
+
+// spfQualifiers maps SPF qualifiers to the result they produce on a match
+var spfQualifiers = map[byte]string{'+': "pass", '-': "fail", '~': "softfail", '?': "neutral"}
+
+// spfModifierRegex matches a modifier name per RFC 7208 section 12
+var spfModifierRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9\-_.]*=`)
+
+// spfCIDRRegex and spfCIDR6Regex match the dual-cidr-length of a and mx
+var (
+	spfCIDRRegex  = regexp.MustCompile(`/(\d+)$`)
+	spfCIDR6Regex = regexp.MustCompile(`//(\d+)$`)
+)
+
+// errSPFMacroUnresolved marks a macro that needs sender details to expand
+var errSPFMacroUnresolved = errors.New("macro depends on the sender")
+
+// SPFTerm represents one mechanism or modifier of an SPF record
+type SPFTerm struct {
+	Raw       string   `json:"raw"`
+	Qualifier string   `json:"qualifier,omitempty"`
+	Type      string   `json:"type"`
+	Value     string   `json:"value,omitempty"`
+	Prefix4   int      `json:"prefix4,omitempty"`
+	Prefix6   int      `json:"prefix6,omitempty"`
+	Lookups   int      `json:"dns_lookups,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+	Include   *SPFNode `json:"include,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// SPFNode represents an SPF record and the records it includes
+type SPFNode struct {
+	Domain string    `json:"domain"`
+	Record string    `json:"record,omitempty"`
+	Terms  []SPFTerm `json:"terms,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// spfMacroContext holds the values SPF macros expand to. A nil IP means the
+// sender is unknown and sender-dependent macros cannot be expanded.
+type spfMacroContext struct {
+	ip     net.IP
+	sender string
+	helo   string
+	domain string
+}
+
+// lookupSPFRecords returns the v=spf1 TXT records of a domain. A domain
+// without TXT records is not an error.
+func lookupSPFRecords(domain string) ([]string, error) {
+	txtRecords, err := net.LookupTXT(domain)
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []string
+	for _, txt := range txtRecords {
+		lower := strings.ToLower(txt)
+		if lower == "v=spf1" || strings.HasPrefix(lower, "v=spf1 ") {
+			records = append(records, txt)
+		}
+	}
+	return records, nil
+}
+
+// isVoidLookup reports whether a lookup error is NXDOMAIN or an empty answer
+func isVoidLookup(err error) bool {
+	dnsErr, ok := err.(*net.DNSError)
+	return ok && dnsErr.IsNotFound
+}
+
+// parseSPFTerm parses a single SPF term per the RFC 7208 section 12 ABNF
+func parseSPFTerm(raw string) SPFTerm {
+	term := SPFTerm{Raw: raw}
+
+	// Modifiers: name=value
+	if spfModifierRegex.MatchString(raw) {
+		kv := strings.SplitN(raw, "=", 2)
+		term.Type = strings.ToLower(kv[0])
+		term.Value = kv[1]
+		if term.Type != "redirect" && term.Type != "exp" {
+			term.Type = "modifier"
+		} else if term.Value == "" {
+			term.Error = fmt.Sprintf("%s requires a domain", kv[0])
+		}
+		return term
+	}
+
+	term.Qualifier = "+"
+	if q := raw[0]; spfQualifiers[q] != "" {
+		term.Qualifier = string(q)
+		raw = raw[1:]
+	}
+
+	name := raw
+	rest := ""
+	if i := strings.IndexAny(raw, ":/"); i >= 0 {
+		name, rest = raw[:i], raw[i:]
+	}
+	term.Type = strings.ToLower(name)
+
+	switch term.Type {
+	case "all":
+		if rest != "" {
+			term.Error = "all takes no arguments"
+		}
+	case "include", "exists":
+		if !strings.HasPrefix(rest, ":") || len(rest) < 2 {
+			term.Error = fmt.Sprintf("%s requires a domain", term.Type)
+			break
+		}
+		term.Value = rest[1:]
+	case "a", "mx":
+		term.Prefix4, term.Prefix6 = 32, 128
+		if m := spfCIDR6Regex.FindStringSubmatch(rest); m != nil {
+			term.Prefix6, _ = strconv.Atoi(m[1])
+			rest = strings.TrimSuffix(rest, m[0])
+		}
+		if m := spfCIDRRegex.FindStringSubmatch(rest); m != nil {
+			term.Prefix4, _ = strconv.Atoi(m[1])
+			rest = strings.TrimSuffix(rest, m[0])
+		}
+		if term.Prefix4 > 32 || term.Prefix6 > 128 {
+			term.Error = "invalid CIDR length"
+		}
+		if strings.HasPrefix(rest, ":") {
+			term.Value = rest[1:]
+		} else if rest != "" {
+			term.Error = fmt.Sprintf("invalid %s arguments %q", term.Type, rest)
+		}
+	case "ptr":
+		if strings.HasPrefix(rest, ":") {
+			term.Value = rest[1:]
+		} else if rest != "" {
+			term.Error = fmt.Sprintf("invalid ptr arguments %q", rest)
+		}
+	case "ip4", "ip6":
+		if !strings.HasPrefix(rest, ":") {
+			term.Error = fmt.Sprintf("%s requires an address", term.Type)
+			break
+		}
+		term.Value = rest[1:]
+		network := term.Value
+		if !strings.Contains(network, "/") {
+			if term.Type == "ip4" {
+				network += "/32"
+			} else {
+				network += "/128"
+			}
+		}
+		ip, ipNet, err := net.ParseCIDR(network)
+		isIPv4 := err == nil && ip.To4() != nil && !strings.Contains(term.Value, ":")
+		if err != nil || (term.Type == "ip4") != isIPv4 {
+			term.Error = fmt.Sprintf("invalid %s network %q", term.Type, term.Value)
+			break
+		}
+		ones, _ := ipNet.Mask.Size()
+		if term.Type == "ip4" {
+			term.Prefix4 = ones
+		} else {
+			term.Prefix6 = ones
+		}
+		term.Addresses = []string{ipNet.String()}
+	default:
+		term.Error = fmt.Sprintf("unknown mechanism %q", name)
+	}
+
+	return term
+}
+
+// reverseNibbles renders an IPv6 address as dot separated nibbles
+func reverseNibbles(ip net.IP) string {
+	hexIP := hex.EncodeToString(ip.To16())
+	nibbles := make([]string, len(hexIP))
+	for i, c := range hexIP {
+		nibbles[i] = string(c)
+	}
+	return strings.Join(nibbles, ".")
+}
+
+// expandSPFMacros expands an SPF macro-string (RFC 7208 section 7). The c, r
+// and t macros are only allowed in explanation strings.
+func expandSPFMacros(s string, ctx spfMacroContext, explanation bool) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			out.WriteByte(s[i])
+			continue
+		}
+		if i+1 >= len(s) {
+			return "", fmt.Errorf("trailing %% in %q", s)
+		}
+		i++
+		switch s[i] {
+		case '%':
+			out.WriteByte('%')
+			continue
+		case '_':
+			out.WriteByte(' ')
+			continue
+		case '-':
+			out.WriteString("%20")
+			continue
+		case '{':
 		default:
-			result.SSL.KeySize = 0
+			return "", fmt.Errorf("invalid macro %%%c in %q", s[i], s)
+		}
+
+		end := strings.IndexByte(s[i:], '}')
+		if end < 2 {
+			return "", fmt.Errorf("unterminated macro in %q", s)
+		}
+		macro := s[i+1 : i+end]
+		i += end
+
+		letter := macro[0]
+		escape := letter >= 'A' && letter <= 'Z'
+		letter |= 0x20
+		spec := macro[1:]
+		digits := 0
+		for len(spec) > 0 && spec[0] >= '0' && spec[0] <= '9' {
+			digits = digits*10 + int(spec[0]-'0')
+			spec = spec[1:]
+			if digits == 0 {
+				return "", fmt.Errorf("invalid macro transformer in %%{%s}", macro)
+			}
+		}
+		reverse := false
+		if len(spec) > 0 && (spec[0] == 'r' || spec[0] == 'R') {
+			reverse = true
+			spec = spec[1:]
+		}
+		delimiters := "."
+		if spec != "" {
+			if strings.Trim(spec, ".-+,/_=") != "" {
+				return "", fmt.Errorf("invalid macro delimiter in %%{%s}", macro)
+			}
+			delimiters = spec
+		}
+
+		localPart, senderDomain := "postmaster", ctx.sender
+		if at := strings.LastIndex(ctx.sender, "@"); at >= 0 {
+			if at > 0 {
+				localPart = ctx.sender[:at]
+			}
+			senderDomain = ctx.sender[at+1:]
+		}
+
+		var value string
+		switch letter {
+		case 'd':
+			value = ctx.domain
+		case 's', 'l', 'o', 'i', 'p', 'v', 'h':
+			if ctx.ip == nil {
+				return "", errSPFMacroUnresolved
+			}
+			switch letter {
+			case 's':
+				value = localPart + "@" + senderDomain
+			case 'l':
+				value = localPart
+			case 'o':
+				value = senderDomain
+			case 'i':
+				if ip4 := ctx.ip.To4(); ip4 != nil {
+					value = ip4.String()
+				} else {
+					value = reverseNibbles(ctx.ip)
+				}
+			case 'p':
+				value = "unknown"
+			case 'v':
+				value = "ip6"
+				if ctx.ip.To4() != nil {
+					value = "in-addr"
+				}
+			case 'h':
+				value = ctx.helo
+			}
+		case 'c', 'r', 't':
+			if !explanation {
+				return "", fmt.Errorf("macro %%{%c} is only allowed in exp", letter)
+			}
+			switch letter {
+			case 'c':
+				if ctx.ip == nil {
+					return "", errSPFMacroUnresolved
+				}
+				value = ctx.ip.String()
+			case 'r':
+				value = "netcheck"
+			case 't':
+				value = strconv.FormatInt(time.Now().Unix(), 10)
+			}
+		default:
+			return "", fmt.Errorf("unknown macro letter in %%{%s}", macro)
+		}
+
+		parts := strings.FieldsFunc(value, func(r rune) bool { return strings.ContainsRune(delimiters, r) })
+		if reverse {
+			for l, r := 0, len(parts)-1; l < r; l, r = l+1, r-1 {
+				parts[l], parts[r] = parts[r], parts[l]
+			}
+		}
+		if digits > 0 && digits < len(parts) {
+			parts = parts[len(parts)-digits:]
+		}
+		value = strings.Join(parts, ".")
+		if escape {
+			value = url.QueryEscape(value)
+		}
+		out.WriteString(value)
+	}
+
+	// Domain names longer than 253 characters lose labels from the left
+	result := out.String()
+	for !explanation && len(result) > 253 && strings.Contains(result, ".") {
+		result = result[strings.IndexByte(result, '.')+1:]
+	}
+	return result, nil
+}
+
+// spfWalker statically walks an SPF record and everything it references,
+// counting DNS lookups and collecting the address ranges it authorizes
+type spfWalker struct {
+	lookups      int
+	voids        int
+	allQualifier string
+	errors       []string
+	warnings     []string
+	ipv4         map[string]bool
+	ipv6         map[string]bool
+	visiting     map[string]bool
+}
+
+func newSPFWalker() *spfWalker {
+	return &spfWalker{
+		ipv4:     make(map[string]bool),
+		ipv6:     make(map[string]bool),
+		visiting: make(map[string]bool),
+	}
+}
+
+func (w *spfWalker) errorf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if !contains(w.errors, msg) {
+		w.errors = append(w.errors, msg)
+	}
+}
+
+func (w *spfWalker) warnf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if !contains(w.warnings, msg) {
+		w.warnings = append(w.warnings, msg)
+	}
+}
+
+// countLookup records a DNS-querying term and checks the lookup limit. It reports
+// false once the lookup or void limit is exceeded: receivers stop with permerror at
+// that point, so the term is not resolved and a hostile record tree cannot fan out.
+func (w *spfWalker) countLookup(term *SPFTerm) bool {
+	w.lookups++
+	term.Lookups++
+	if w.lookups == spfMaxDNSLookups+1 {
+		w.errorf("More than %d DNS lookups; receivers return permerror", spfMaxDNSLookups)
+	}
+	if w.lookups > spfMaxDNSLookups || w.voids > spfMaxVoidLookups {
+		term.Error = "not evaluated: lookup limit exceeded"
+		return false
+	}
+	return true
+}
+
+// countVoid records a lookup that returned no records
+func (w *spfWalker) countVoid(term *SPFTerm, name string) {
+	w.voids++
+	w.warnf("Void lookup for %s", name)
+	if w.voids == spfMaxVoidLookups+1 {
+		w.errorf("More than %d void lookups; receivers return permerror", spfMaxVoidLookups)
+	}
+}
+
+// authorize records addresses a passing term authorizes
+func (w *spfWalker) authorize(term *SPFTerm, ips []net.IP) {
+	for _, ip := range ips {
+		var network string
+		if ip4 := ip.To4(); ip4 != nil {
+			network = (&net.IPNet{IP: ip4.Mask(net.CIDRMask(term.Prefix4, 32)), Mask: net.CIDRMask(term.Prefix4, 32)}).String()
+		} else {
+			network = (&net.IPNet{IP: ip.Mask(net.CIDRMask(term.Prefix6, 128)), Mask: net.CIDRMask(term.Prefix6, 128)}).String()
+		}
+		if !contains(term.Addresses, network) {
+			term.Addresses = append(term.Addresses, network)
+		}
+	}
+}
+
+// flattened returns the collected networks sorted
+func (w *spfWalker) flattened(set map[string]bool) []string {
+	list := make([]string, 0, len(set))
+	for network := range set {
+		list = append(list, network)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// walk parses record (published at domain) and follows includes and redirect.
+// pass is false below a non-pass include, whose ranges are not authorized.
+// top is set for the queried domain and its redirect targets.
+func (w *spfWalker) walk(domain, record string, pass, top bool) *SPFNode {
+	node := &SPFNode{Domain: domain, Record: record}
+	w.visiting[strings.ToLower(domain)] = true
+	defer delete(w.visiting, strings.ToLower(domain))
+
+	ctx := spfMacroContext{domain: domain}
+	fields := strings.Fields(record)[1:]
+	if strings.Contains(record, "  ") {
+		w.warnf("%s: SPF record contains repeated spaces", domain)
+	}
+
+	redirect := -1
+	seenAll := false
+	modifiers := make(map[string]bool)
+
+	for _, raw := range fields {
+		term := parseSPFTerm(raw)
+		if term.Error != "" {
+			w.errorf("%s: syntax error in %q: %s", domain, raw, term.Error)
+			node.Terms = append(node.Terms, term)
+			continue
+		}
+		if seenAll && term.Type != "exp" && term.Type != "redirect" && term.Type != "modifier" {
+			w.warnf("%s: %q after all is never evaluated", domain, raw)
+		}
+
+		// Expand the domain-spec of terms that take one
+		target := domain
+		if term.Value != "" && term.Type != "ip4" && term.Type != "ip6" && term.Type != "modifier" {
+			expanded, err := expandSPFMacros(term.Value, ctx, term.Type == "exp")
+			switch {
+			case err == errSPFMacroUnresolved:
+				target = ""
+			case err != nil:
+				w.errorf("%s: %v", domain, err)
+				term.Error = err.Error()
+				node.Terms = append(node.Terms, term)
+				continue
+			default:
+				target = expanded
+			}
+		}
+
+		switch term.Type {
+		case "all":
+			seenAll = true
+			if top {
+				w.allQualifier = term.Qualifier
+			}
+			if pass && term.Qualifier == "+" {
+				w.warnf("%s: +all authorizes every host on the internet", domain)
+			}
+		case "ip4", "ip6":
+			if ip, ipNet, err := net.ParseCIDR(term.Value); err == nil && !ip.Equal(ipNet.IP) {
+				w.warnf("%s: %s has host bits set (network is %s)", domain, raw, ipNet)
+			}
+			if pass && term.Qualifier == "+" {
+				if term.Type == "ip4" {
+					w.ipv4[term.Addresses[0]] = true
+				} else {
+					w.ipv6[term.Addresses[0]] = true
+				}
+			}
+		case "include":
+			if !w.countLookup(&term) {
+				break
+			}
+			if target == "" {
+				w.warnf("%s: include:%s depends on the sender and was not evaluated", domain, term.Value)
+				break
+			}
+			if w.visiting[strings.ToLower(target)] {
+				w.errorf("%s: include loop through %s", domain, target)
+				break
+			}
+			records, err := lookupSPFRecords(target)
+			switch {
+			case err != nil:
+				term.Error = err.Error()
+				w.warnf("%s: include:%s lookup failed (temperror): %v", domain, target, err)
+			case len(records) == 0:
+				w.countVoid(&term, target)
+				term.Error = "no SPF record"
+				w.errorf("%s: include:%s has no SPF record; receivers return permerror", domain, target)
+			default:
+				if len(records) > 1 {
+					w.errorf("%s: include:%s publishes multiple SPF records", domain, target)
+				}
+				term.Include = w.walk(target, records[0], pass && term.Qualifier == "+", false)
+			}
+		case "a":
+			if !w.countLookup(&term) || target == "" {
+				break
+			}
+			ips, err := net.LookupIP(target)
+			if err != nil {
+				if isVoidLookup(err) {
+					w.countVoid(&term, target)
+				} else {
+					term.Error = err.Error()
+				}
+				break
+			}
+			w.authorize(&term, ips)
+		case "mx":
+			if !w.countLookup(&term) || target == "" {
+				break
+			}
+			mxs, err := net.LookupMX(target)
+			if err != nil {
+				if isVoidLookup(err) {
+					w.countVoid(&term, target)
+				} else {
+					term.Error = err.Error()
+				}
+				break
+			}
+			if len(mxs) > spfMaxNames {
+				w.errorf("%s: mx for %s returns %d hosts, more than %d", domain, target, len(mxs), spfMaxNames)
+			}
+			for i, mx := range mxs {
+				if i == spfMaxNames {
+					break
+				}
+				if ips, err := net.LookupIP(strings.TrimSuffix(mx.Host, ".")); err == nil {
+					w.authorize(&term, ips)
+				}
+			}
+		case "ptr":
+			w.countLookup(&term)
+			w.warnf("%s: ptr is slow and unreliable; RFC 7208 says it SHOULD NOT be used", domain)
+		case "exists":
+			if !w.countLookup(&term) || target == "" {
+				break
+			}
+			if _, err := net.LookupIP(target); err != nil && isVoidLookup(err) {
+				w.countVoid(&term, target)
+			}
+		case "redirect", "exp":
+			if modifiers[term.Type] {
+				w.errorf("%s: %s appears more than once", domain, term.Type)
+			}
+			modifiers[term.Type] = true
+			if term.Type == "redirect" {
+				redirect = len(node.Terms)
+			}
+		case "modifier":
+			w.warnf("%s: unknown modifier %q is ignored", domain, raw)
+		}
+
+		// ip4/ip6/a/mx ranges authorized by this term
+		if pass && term.Qualifier == "+" && (term.Type == "a" || term.Type == "mx") {
+			for _, network := range term.Addresses {
+				if strings.Contains(network, ":") {
+					w.ipv6[network] = true
+				} else {
+					w.ipv4[network] = true
+				}
+			}
+		}
+
+		node.Terms = append(node.Terms, term)
+	}
+
+	// redirect is only used when no mechanism matched, so all makes it dead
+	if redirect >= 0 {
+		term := &node.Terms[redirect]
+		if seenAll {
+			w.warnf("%s: redirect is ignored because the record contains all", domain)
+		} else if w.countLookup(term) {
+			target, err := expandSPFMacros(term.Value, ctx, false)
+			switch {
+			case err == errSPFMacroUnresolved:
+				w.warnf("%s: redirect=%s depends on the sender and was not evaluated", domain, term.Value)
+			case err != nil:
+				w.errorf("%s: %v", domain, err)
+			case w.visiting[strings.ToLower(target)]:
+				w.errorf("%s: redirect loop through %s", domain, target)
+			default:
+				records, err := lookupSPFRecords(target)
+				switch {
+				case err != nil:
+					term.Error = err.Error()
+					w.warnf("%s: redirect=%s lookup failed (temperror): %v", domain, target, err)
+				case len(records) == 0:
+					w.countVoid(term, target)
+					term.Error = "no SPF record"
+					w.errorf("%s: redirect=%s has no SPF record; receivers return permerror", domain, target)
+				default:
+					term.Include = w.walk(target, records[0], pass, top)
+				}
+			}
+		}
+	} else if !seenAll && top {
+		w.warnf("%s: no all mechanism or redirect; unmatched senders get neutral", domain)
+	}
+
+	if top && w.allQualifier == "?" {
+		w.warnf("%s: ?all gives unmatched senders a neutral result", domain)
+	}
+
+	return node
+}
+
+func handleSPF(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/spf", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	spfInfo := checker.CheckSPF(domain)
+	if ttl, ok := routeTTL["/api/v1/spf"]; ok {
+		apiCache.Set(key, spfInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    spfInfo,
+	})
+}
+
+// -----------------------------
+// SPF check_host() simulation
+// -----------------------------
+
+// SPFTraceStep represents one step of a check_host() evaluation
+type SPFTraceStep struct {
+	Depth  int    `json:"depth"`
+	Domain string `json:"domain"`
+	Term   string `json:"term,omitempty"`
+	Result string `json:"result"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SPFCheckResult represents the outcome of check_host() for a sending IP
+type SPFCheckResult struct {
+	Domain        string         `json:"domain"`
+	IP            string         `json:"ip"`
+	HELO          string         `json:"helo,omitempty"`
+	MailFrom      string         `json:"mail_from"`
+	Result        string         `json:"result"` // pass, fail, softfail, neutral, none, permerror, temperror
+	MatchedTerm   string         `json:"matched_term,omitempty"`
+	MatchedDomain string         `json:"matched_domain,omitempty"`
+	Explanation   string         `json:"explanation,omitempty"`
+	DNSLookups    int            `json:"dns_lookups"`
+	VoidLookups   int            `json:"void_lookups"`
+	Trace         []SPFTraceStep `json:"trace"`
+	Error         string         `json:"error,omitempty"`
+}
+
+// spfEvaluator runs check_host() and records the trace
+type spfEvaluator struct {
+	ip      net.IP
+	sender  string
+	helo    string
+	lookups int
+	voids   int
+	result  *SPFCheckResult
+}
+
+func (e *spfEvaluator) step(depth int, domain, term, result, detail string) {
+	e.result.Trace = append(e.result.Trace, SPFTraceStep{Depth: depth, Domain: domain, Term: term, Result: result, Detail: detail})
+}
+
+// spfError carries a permerror or temperror out of mechanism evaluation
+type spfError struct {
+	result string
+	detail string
+}
+
+func (err *spfError) Error() string { return err.detail }
+
+func spfPermError(format string, args ...interface{}) error {
+	return &spfError{result: "permerror", detail: fmt.Sprintf(format, args...)}
+}
+
+func spfTempError(err error) error {
+	return &spfError{result: "temperror", detail: err.Error()}
+}
+
+// countLookup enforces the DNS lookup limit
+func (e *spfEvaluator) countLookup() error {
+	e.lookups++
+	if e.lookups > spfMaxDNSLookups {
+		return spfPermError("more than %d DNS lookups", spfMaxDNSLookups)
+	}
+	return nil
+}
+
+// lookupErr classifies a DNS error as a void lookup or temperror
+func (e *spfEvaluator) lookupErr(err error) error {
+	if isVoidLookup(err) {
+		e.voids++
+		if e.voids > spfMaxVoidLookups {
+			return spfPermError("more than %d void lookups", spfMaxVoidLookups)
+		}
+		return nil
+	}
+	return spfTempError(err)
+}
+
+// lookupAddrs resolves the A or AAAA records matching the sender's address family
+func (e *spfEvaluator) lookupAddrs(name string) ([]net.IP, error) {
+	network := "ip6"
+	if e.ip.To4() != nil {
+		network = "ip4"
+	}
+	ips, err := net.DefaultResolver.LookupIP(context.Background(), network, name)
+	if err != nil {
+		return nil, e.lookupErr(err)
+	}
+	return ips, nil
+}
+
+// ipInNetwork reports whether ip is inside candidate/prefix for its family
+func ipInNetwork(ip, candidate net.IP, prefix4, prefix6 int) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		c4 := candidate.To4()
+		return c4 != nil && ip4.Mask(net.CIDRMask(prefix4, 32)).Equal(c4.Mask(net.CIDRMask(prefix4, 32)))
+	}
+	if candidate.To4() != nil {
+		return false
+	}
+	return ip.Mask(net.CIDRMask(prefix6, 128)).Equal(candidate.Mask(net.CIDRMask(prefix6, 128)))
+}
+
+// matchTerm evaluates one mechanism against the sender (RFC 7208 section 5)
+func (e *spfEvaluator) matchTerm(term SPFTerm, domain string, depth int) (bool, string, error) {
+	ctx := spfMacroContext{ip: e.ip, sender: e.sender, helo: e.helo, domain: domain}
+	target := domain
+	if term.Value != "" && term.Type != "ip4" && term.Type != "ip6" {
+		expanded, err := expandSPFMacros(term.Value, ctx, false)
+		if err != nil {
+			return false, "", spfPermError("%v", err)
+		}
+		target = expanded
+	}
+
+	switch term.Type {
+	case "all":
+		return true, "", nil
+
+	case "ip4", "ip6":
+		_, ipNet, _ := net.ParseCIDR(term.Addresses[0])
+		if term.Type == "ip4" && e.ip.To4() == nil || term.Type == "ip6" && e.ip.To4() != nil {
+			return false, "address family differs", nil
+		}
+		return ipNet.Contains(e.ip), "", nil
+
+	case "include":
+		if err := e.countLookup(); err != nil {
+			return false, "", err
+		}
+		result := e.checkHost(target, depth+1)
+		switch result {
+		case "pass":
+			return true, fmt.Sprintf("%s returned pass", target), nil
+		case "fail", "softfail", "neutral":
+			return false, fmt.Sprintf("%s returned %s", target, result), nil
+		case "temperror":
+			return false, "", &spfError{result: "temperror", detail: fmt.Sprintf("%s returned temperror", target)}
+		default:
+			return false, "", spfPermError("%s returned %s", target, result)
+		}
+
+	case "a":
+		if err := e.countLookup(); err != nil {
+			return false, "", err
+		}
+		ips, err := e.lookupAddrs(target)
+		if err != nil {
+			return false, "", err
+		}
+		for _, ip := range ips {
+			if ipInNetwork(e.ip, ip, term.Prefix4, term.Prefix6) {
+				return true, fmt.Sprintf("%s has address %s", target, ip), nil
+			}
+		}
+		return false, fmt.Sprintf("%s has %d addresses", target, len(ips)), nil
+
+	case "mx":
+		if err := e.countLookup(); err != nil {
+			return false, "", err
+		}
+		mxs, err := net.LookupMX(target)
+		if err != nil {
+			return false, "", e.lookupErr(err)
+		}
+		if len(mxs) > spfMaxNames {
+			return false, "", spfPermError("%s has more than %d MX records", target, spfMaxNames)
+		}
+		for _, mx := range mxs {
+			host := strings.TrimSuffix(mx.Host, ".")
+			ips, err := e.lookupAddrs(host)
+			if err != nil {
+				return false, "", err
+			}
+			for _, ip := range ips {
+				if ipInNetwork(e.ip, ip, term.Prefix4, term.Prefix6) {
+					return true, fmt.Sprintf("MX %s has address %s", host, ip), nil
+				}
+			}
+		}
+		return false, fmt.Sprintf("%s has %d MX hosts", target, len(mxs)), nil
+
+	case "ptr":
+		if err := e.countLookup(); err != nil {
+			return false, "", err
+		}
+		names, err := net.LookupAddr(e.ip.String())
+		if err != nil {
+			// PTR failures never produce an error result (section 5.5)
+			return false, "no PTR record", nil
+		}
+		for i, name := range names {
+			if i == spfMaxNames {
+				break
+			}
+			name = strings.ToLower(strings.TrimSuffix(name, "."))
+			if name != strings.ToLower(target) && !strings.HasSuffix(name, "."+strings.ToLower(target)) {
+				continue
+			}
+			ips, err := net.LookupIP(name)
+			if err != nil {
+				continue
+			}
+			for _, ip := range ips {
+				if ip.Equal(e.ip) {
+					return true, fmt.Sprintf("validated PTR name %s", name), nil
+				}
+			}
+		}
+		return false, "no validated PTR name in " + target, nil
+
+	case "exists":
+		if err := e.countLookup(); err != nil {
+			return false, "", err
+		}
+		ips, err := net.DefaultResolver.LookupIP(context.Background(), "ip4", target)
+		if err != nil {
+			if err := e.lookupErr(err); err != nil {
+				return false, "", err
+			}
+			return false, fmt.Sprintf("%s does not exist", target), nil
+		}
+		return len(ips) > 0, fmt.Sprintf("%s exists", target), nil
+	}
+
+	return false, "", spfPermError("unknown mechanism %q", term.Raw)
+}
+
+// checkHost implements check_host() (RFC 7208 section 4) for domain
+func (e *spfEvaluator) checkHost(domain string, depth int) string {
+	domain = strings.TrimSuffix(domain, ".")
+	labels := strings.Split(domain, ".")
+	for _, label := range labels {
+		if label == "" || len(label) > 63 {
+			e.step(depth, domain, "", "none", "invalid domain name")
+			return "none"
+		}
+	}
+	if len(labels) < 2 {
+		e.step(depth, domain, "", "none", "domain is not fully qualified")
+		return "none"
+	}
+
+	records, err := lookupSPFRecords(domain)
+	if err != nil {
+		e.step(depth, domain, "", "temperror", err.Error())
+		return "temperror"
+	}
+	switch len(records) {
+	case 0:
+		e.step(depth, domain, "", "none", "no SPF record")
+		return "none"
+	case 1:
+		e.step(depth, domain, "", "record", records[0])
+	default:
+		e.step(depth, domain, "", "permerror", "multiple SPF records")
+		return "permerror"
+	}
+
+	// Any syntax error makes the whole record a permerror (section 4.6)
+	var terms []SPFTerm
+	var redirect, exp *SPFTerm
+	for _, raw := range strings.Fields(records[0])[1:] {
+		term := parseSPFTerm(raw)
+		if term.Error != "" {
+			e.step(depth, domain, raw, "permerror", term.Error)
+			return "permerror"
+		}
+		switch term.Type {
+		case "redirect", "exp":
+			if term.Type == "redirect" && redirect != nil || term.Type == "exp" && exp != nil {
+				e.step(depth, domain, raw, "permerror", term.Type+" appears more than once")
+				return "permerror"
+			}
+			t := term
+			if term.Type == "redirect" {
+				redirect = &t
+			} else {
+				exp = &t
+			}
+		case "modifier":
+		default:
+			terms = append(terms, term)
+		}
+	}
+
+	for _, term := range terms {
+		matched, detail, err := e.matchTerm(term, domain, depth)
+		if err != nil {
+			serr, ok := err.(*spfError)
+			result := "permerror"
+			if ok {
+				result = serr.result
+			}
+			e.step(depth, domain, term.Raw, result, err.Error())
+			return result
+		}
+		if !matched {
+			e.step(depth, domain, term.Raw, "no match", detail)
+			continue
+		}
+
+		result := spfQualifiers[term.Qualifier[0]]
+		e.step(depth, domain, term.Raw, "match", detail)
+		if depth == 0 {
+			e.result.MatchedTerm = term.Raw
+			e.result.MatchedDomain = domain
+		}
+		if result == "fail" && exp != nil && depth == 0 {
+			e.result.Explanation = e.explanation(exp.Value, domain)
+		}
+		return result
+	}
+
+	if redirect != nil {
+		if err := e.countLookup(); err != nil {
+			e.step(depth, domain, redirect.Raw, "permerror", err.Error())
+			return "permerror"
+		}
+		target, err := expandSPFMacros(redirect.Value, spfMacroContext{ip: e.ip, sender: e.sender, helo: e.helo, domain: domain}, false)
+		if err != nil {
+			e.step(depth, domain, redirect.Raw, "permerror", err.Error())
+			return "permerror"
+		}
+		e.step(depth, domain, redirect.Raw, "redirect", target)
+		result := e.checkHost(target, depth)
+		if result == "none" {
+			e.step(depth, target, "", "permerror", "redirect target has no SPF record")
+			return "permerror"
+		}
+		return result
+	}
+
+	e.step(depth, domain, "", "neutral", "no mechanism matched")
+	return "neutral"
+}
+
+// explanation fetches and expands the exp= explanation string (section 6.2)
+func (e *spfEvaluator) explanation(spec, domain string) string {
+	ctx := spfMacroContext{ip: e.ip, sender: e.sender, helo: e.helo, domain: domain}
+	target, err := expandSPFMacros(spec, ctx, false)
+	if err != nil {
+		return ""
+	}
+	txts, err := net.LookupTXT(target)
+	if err != nil || len(txts) != 1 {
+		return ""
+	}
+	text, err := expandSPFMacros(txts[0], ctx, true)
+	if err != nil {
+		return ""
+	}
+	return text
+}
+
+// CheckSPFHost runs check_host() for a sending IP, HELO name and MAIL FROM address
+func (nc *NetChecker) CheckSPFHost(domain, ipStr, helo, mailFrom string) SPFCheckResult {
+	result := SPFCheckResult{Domain: domain, IP: ipStr, HELO: helo, Trace: []SPFTraceStep{}}
+
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		result.Error = fmt.Sprintf("Invalid IP address: %s", ipStr)
+		return result
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	// With an empty MAIL FROM the HELO identity is checked as postmaster@helo (section 2.4)
+	sender := mailFrom
+	if sender == "" {
+		if helo != "" {
+			sender = "postmaster@" + helo
+		} else {
+			sender = "postmaster@" + domain
+		}
+	} else if !strings.Contains(sender, "@") {
+		sender = "postmaster@" + sender
+	}
+	result.MailFrom = sender
+
+	e := &spfEvaluator{ip: ip, sender: sender, helo: helo, result: &result}
+	result.Result = e.checkHost(domain, 0)
+	result.DNSLookups = e.lookups
+	result.VoidLookups = e.voids
+
+	return result
+}
+
+func handleSPFCheck(c *gin.Context) {
+	domain := c.Query("domain")
+	ip := c.Query("ip")
+	if domain == "" || ip == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain and ip parameters are required",
+		})
+		return
+	}
+	helo := c.Query("helo")
+	mailFrom := c.Query("mail_from")
+
+	key := cacheKey("/api/v1/spf/check", map[string]string{"domain": domain, "ip": ip, "helo": helo, "mail_from": mailFrom})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	checkResult := checker.CheckSPFHost(domain, ip, helo, mailFrom)
+	if ttl, ok := routeTTL["/api/v1/spf/check"]; ok {
+		apiCache.Set(key, checkResult, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    checkResult,
+	})
+}
+
+// CheckDKIM checks DKIM (DomainKeys Identified Mail) records. Custom selectors
+// are tried along with the provider selector dictionary.
+func (nc *NetChecker) CheckDKIM(domain string, customSelectors ...string) DKIMInfo {
+	info := DKIMInfo{Selectors: []string{}, Keys: []DKIMKeyInfo{}}
+
+	// Build the candidate list: custom selectors first, then the dictionary
+	providers := make(map[string]string)
+	var candidates []string
+	for _, selector := range customSelectors {
+		selector = strings.ToLower(strings.TrimSpace(selector))
+		if selector != "" && providers[selector] == "" {
+			providers[selector] = "Custom"
+			candidates = append(candidates, selector)
+		}
+	}
+	for _, entry := range dkimSelectorDictionary {
+		for _, selector := range entry.Selectors {
+			if _, ok := providers[selector]; !ok {
+				providers[selector] = entry.Provider
+				candidates = append(candidates, selector)
+			}
+		}
+	}
+	info.Tried = len(candidates)
+
+	// Query selectors concurrently
+	keys := make([]*DKIMKeyInfo, len(candidates))
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 16)
+	for i, selector := range candidates {
+		wg.Add(1)
+		go func(i int, selector string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			key, ok, err := lookupDKIMKey(domain, selector)
+			errs[i] = err
+			if ok {
+				if key.Provider == "" && providers[selector] != "Generic" {
+					key.Provider = providers[selector]
+				}
+				keys[i] = &key
+			}
+		}(i, selector)
+	}
+	wg.Wait()
+
+	var firstErr error
+	for i, err := range errs {
+		if err != nil {
+			info.Failed = append(info.Failed, candidates[i])
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if firstErr != nil {
+		info.Error = fmt.Sprintf("DNS lookup failed for %d of %d selectors: %v", len(info.Failed), info.Tried, firstErr)
+	}
+
+	for _, key := range keys {
+		if key == nil {
+			continue
+		}
+		info.Selectors = append(info.Selectors, key.Selector)
+		info.Keys = append(info.Keys, *key)
+		if key.Valid && !key.Revoked {
+			info.Valid = true
+		}
+	}
+
+	if len(info.Keys) > 0 {
+		info.Configured = true
+		info.Details = fmt.Sprintf("DKIM records found for selectors: %v", info.Selectors)
+		if !info.Valid {
+			info.Details = fmt.Sprintf("DKIM records found for selectors %v, but none holds a usable key", info.Selectors)
+		}
+	} else if len(info.Failed) > 0 {
+		info.Details = fmt.Sprintf("No DKIM records found, but %d of %d selector lookups failed", len(info.Failed), info.Tried)
+	} else {
+		info.Details = fmt.Sprintf("No DKIM records found for %d known selectors", info.Tried)
+	}
+
+	return info
+}
+
+func handleDKIM(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	selectors := splitHeaderList(c.Query("selectors"))
+	if len(selectors) > 50 {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "At most 50 selectors may be specified",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/dkim", map[string]string{"domain": domain, "selectors": strings.Join(selectors, ",")})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	dkimInfo := checker.CheckDKIM(domain, selectors...)
+	if ttl, ok := routeTTL["/api/v1/dkim"]; ok {
+		apiCache.Set(key, dkimInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    dkimInfo,
+	})
+}
+
+// -----------------------------
+// DKIM selector discovery and key analysis
+// -----------------------------
+
+// dkimSelectorDictionary lists selectors known to be used by mail providers.
+// Custom selectors are tried first; a selector listed by several providers is
+// attributed to the first.
+var dkimSelectorDictionary = []struct {
+	Provider  string
+	Selectors []string
+}{
+	{"Google Workspace", []string{"google", "google2048", "20161025", "20210112", "20230601"}},
+	{"Microsoft 365", []string{"selector1", "selector2"}},
+	{"Mailchimp", []string{"k1", "k2", "k3", "mte1", "mte2", "mandrill"}},
+	{"SendGrid", []string{"s1", "s2", "smtpapi", "sendgrid"}},
+	{"Mailgun", []string{"mailo", "smtp", "krs", "pic", "mg"}},
+	{"Amazon SES", []string{"amazonses"}},
+	{"Postmark", []string{"pm", "pm-bounces"}},
+	{"SparkPost", []string{"scph0316", "scph1122", "sparkpost"}},
+	{"Brevo", []string{"mail", "sib"}},
+	{"Mailjet", []string{"mailjet"}},
+	{"HubSpot", []string{"hs1", "hs2"}},
+	{"Salesforce", []string{"200608", "et", "sf1", "sf2"}},
+	{"Zendesk", []string{"zendesk1", "zendesk2"}},
+	{"Klaviyo", []string{"kl", "kl2"}},
+	{"Constant Contact", []string{"ctct1", "ctct2"}},
+	{"Intercom", []string{"intercom"}},
+	{"Zoho", []string{"zoho", "zmail"}},
+	{"Fastmail", []string{"fm1", "fm2", "fm3"}},
+	{"Proton", []string{"protonmail", "protonmail2", "protonmail3"}},
+	{"iCloud", []string{"sig1"}},
+	{"Yahoo", []string{"s1024", "s2048"}},
+	{"Cisco", []string{"iport"}},
+	{"Generic", []string{"default", "dkim", "key1", "key2", "selector", "dk", "email", "mx", "s", "x"}},
+}
+
+// dkimCNAMEProviders attributes delegated selectors by CNAME target suffix
+var dkimCNAMEProviders = map[string]string{
+	"dkim.amazonses.com":     "Amazon SES",
+	"onmicrosoft.com":        "Microsoft 365",
+	"sendgrid.net":           "SendGrid",
+	"mailgun.org":            "Mailgun",
+	"mcsv.net":               "Mailchimp",
+	"mandrillapp.com":        "Mailchimp",
+	"hubspotemail.net":       "HubSpot",
+	"sparkpostmail.com":      "SparkPost",
+	"mtasv.net":              "Postmark",
+	"zendesk.com":            "Zendesk",
+	"klaviyodelivery.com":    "Klaviyo",
+	"fm.messagingengine.com": "Fastmail",
+	"protonmail.ch":          "Proton",
+}
+
+// dkimMinKeyBits is the minimum RSA key size verifiers accept (RFC 8301)
+const dkimMinKeyBits = 1024
+
+// DKIMKeyInfo represents a DKIM public key record
+type DKIMKeyInfo struct {
+	Selector       string   `json:"selector"`
+	Provider       string   `json:"provider,omitempty"`
+	Record         string   `json:"record"`
+	CNAME          string   `json:"cname,omitempty"`
+	KeyType        string   `json:"key_type"`
+	Bits           int      `json:"bits,omitempty"`
+	HashAlgorithms []string `json:"hash_algorithms,omitempty"`
+	ServiceTypes   []string `json:"service_types,omitempty"`
+	Flags          []string `json:"flags,omitempty"`
+	Testing        bool     `json:"testing"`
+	Revoked        bool     `json:"revoked"`
+	Weak           bool     `json:"weak"`
+	Notes          string   `json:"notes,omitempty"`
+	Issues         []string `json:"issues,omitempty"`
+	Valid          bool     `json:"valid"`
+
+	publicKey crypto.PublicKey // parsed p=, used to verify signatures
+}
+
+// parseTagList splits a DKIM-style tag=value list (RFC 6376 section 3.2).
+// Duplicate and malformed tags are returned as issues.
+func parseTagList(record string) (map[string]string, []string, []string) {
+	tags := make(map[string]string)
+	var order, issues []string
+	for _, part := range strings.Split(record, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			issues = append(issues, fmt.Sprintf("Malformed tag %q", part))
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(kv[0]))
+		value := strings.Join(strings.Fields(kv[1]), "")
+		if _, dup := tags[name]; dup {
+			issues = append(issues, fmt.Sprintf("Duplicate tag %s", name))
+			continue
+		}
+		tags[name] = value
+		order = append(order, name)
+	}
+	return tags, order, issues
+}
+
+// splitColonList splits a colon separated DKIM tag value
+func splitColonList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ":") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, strings.ToLower(item))
+		}
+	}
+	return items
+}
+
+// parseDKIMKey parses a DKIM key record (RFC 6376 section 3.6.1)
+func parseDKIMKey(selector, record string) DKIMKeyInfo {
+	key := DKIMKeyInfo{Selector: selector, Record: record, KeyType: "rsa"}
+
+	tags, order, issues := parseTagList(record)
+	key.Issues = issues
+	syntaxOK := len(issues) == 0
+	if v, ok := tags["v"]; ok {
+		if v != "DKIM1" {
+			syntaxOK = false
+			key.Issues = append(key.Issues, fmt.Sprintf("Invalid version v=%s", v))
+		} else if order[0] != "v" {
+			syntaxOK = false
+			key.Issues = append(key.Issues, "v= must be the first tag")
+		}
+	}
+	if k, ok := tags["k"]; ok {
+		key.KeyType = strings.ToLower(k)
+	}
+	key.HashAlgorithms = splitColonList(tags["h"])
+	key.ServiceTypes = splitColonList(tags["s"])
+	key.Flags = splitColonList(tags["t"])
+	key.Testing = contains(key.Flags, "y")
+	key.Notes = tags["n"]
+
+	if len(key.HashAlgorithms) > 0 && !contains(key.HashAlgorithms, "sha256") {
+		key.Issues = append(key.Issues, "h= does not allow sha256; rsa-sha1 signatures must not be used (RFC 8301)")
+	}
+	if len(key.ServiceTypes) > 0 && !contains(key.ServiceTypes, "*") && !contains(key.ServiceTypes, "email") {
+		key.Issues = append(key.Issues, "s= does not include email; the key cannot sign mail")
+	}
+	if key.Testing {
+		key.Issues = append(key.Issues, "Testing mode (t=y): verifiers may treat failures as unsigned")
+	}
+
+	p, ok := tags["p"]
+	if !ok {
+		key.Issues = append(key.Issues, "Required p= tag is missing")
+		return key
+	}
+	if p == "" {
+		key.Revoked = true
+		key.Issues = append(key.Issues, "Key is revoked (empty p=)")
+		return key
+	}
+
+	der, err := base64.StdEncoding.DecodeString(p)
+	if err != nil {
+		key.Issues = append(key.Issues, fmt.Sprintf("p= is not valid base64: %v", err))
+		return key
+	}
+
+	switch key.KeyType {
+	case "rsa":
+		pub, err := x509.ParsePKIXPublicKey(der)
+		if err != nil {
+			// Some signers publish a bare PKCS#1 RSAPublicKey
+			if rsaKey, perr := x509.ParsePKCS1PublicKey(der); perr == nil {
+				pub, err = rsaKey, nil
+			}
+		}
+		if err != nil {
+			key.Issues = append(key.Issues, fmt.Sprintf("p= is not a valid RSA public key: %v", err))
+			return key
+		}
+		rsaKey, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			key.Issues = append(key.Issues, "k=rsa but p= holds a non-RSA key")
+			return key
+		}
+		key.Bits = rsaKey.N.BitLen()
+		key.publicKey = rsaKey
+		if key.Bits < dkimMinKeyBits {
+			key.Weak = true
+			key.Issues = append(key.Issues, fmt.Sprintf("%d-bit RSA key is below the %d-bit minimum; verifiers reject it", key.Bits, dkimMinKeyBits))
+		} else if key.Bits < 2048 {
+			key.Issues = append(key.Issues, fmt.Sprintf("%d-bit RSA key; 2048 bits is recommended", key.Bits))
+		}
+	case "ed25519":
+		if len(der) != 32 {
+			key.Issues = append(key.Issues, fmt.Sprintf("ed25519 key must be 32 bytes, got %d", len(der)))
+			return key
+		}
+		key.Bits = 256
+		key.publicKey = ed25519.PublicKey(der)
+	default:
+		key.Issues = append(key.Issues, fmt.Sprintf("Unknown key type k=%s", key.KeyType))
+		return key
+	}
+
+	key.Valid = syntaxOK && !key.Weak
+	return key
+}
+
+// lookupDKIMKey fetches and parses selector._domainkey.domain
+func lookupDKIMKey(domain, selector string) (DKIMKeyInfo, bool, error) {
+	name := selector + "._domainkey." + domain
+	txtRecords, err := net.LookupTXT(name)
+	if err != nil {
+		if isVoidLookup(err) {
+			return DKIMKeyInfo{}, false, nil
+		}
+		return DKIMKeyInfo{}, false, err
+	}
+
+	var records []string
+	for _, txt := range txtRecords {
+		lower := strings.ToLower(strings.ReplaceAll(txt, " ", ""))
+		if strings.HasPrefix(lower, "v=dkim1") || strings.Contains(lower, "p=") {
+			records = append(records, txt)
+		}
+	}
+	if len(records) == 0 {
+		return DKIMKeyInfo{}, false, nil
+	}
+
+	key := parseDKIMKey(selector, records[0])
+	if len(records) > 1 {
+		key.Issues = append(key.Issues, fmt.Sprintf("%d key records published; verifiers may pick either", len(records)))
+	}
+	if cname, err := net.LookupCNAME(name); err == nil && !strings.EqualFold(strings.TrimSuffix(cname, "."), name) {
+		key.CNAME = strings.TrimSuffix(cname, ".")
+		for suffix, provider := range dkimCNAMEProviders {
+			if strings.HasSuffix(strings.ToLower(key.CNAME), suffix) {
+				key.Provider = provider
+			}
+		}
+	}
+	return key, true, nil
+}
+
+// CheckDMARC checks DMARC (Domain-based Message Authentication) record,
+// falling back to the organizational domain (RFC 7489 section 6.6.3)
+func (nc *NetChecker) CheckDMARC(domain string) DMARCInfo {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	info := DMARCInfo{Domain: domain}
+
+	orgDomain, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		orgDomain = domain
+	}
+	info.OrgDomain = orgDomain
+
+	// DMARC record is in _dmarc subdomain
+	records, err := lookupDMARCRecords(domain)
+	if err == nil && len(records) == 0 && orgDomain != domain {
+		records, err = lookupDMARCRecords(orgDomain)
+		if len(records) > 0 {
+			info.Domain = orgDomain
+			info.Inherited = true
+		}
+	}
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to lookup DMARC record: %v", err)
+		info.Details = "No DMARC record found"
+		return info
+	}
+	if len(records) == 0 {
+		info.Details = "No DMARC record found"
+		return info
+	}
+
+	info.Configured = true
+	info.Record = records[0]
+	if len(records) > 1 {
+		info.Errors = append(info.Errors, fmt.Sprintf("%d DMARC records published; receivers apply no policy", len(records)))
+	}
+
+	parseDMARCRecord(&info, records[0])
+	verifyDMARCDestinations(&info, info.RUA)
+	verifyDMARCDestinations(&info, info.RUF)
+
+	// Policy applied to mail from the queried domain
+	info.EffectivePolicy = info.Policy
+	if info.Inherited && info.SubdomainPolicy != "" {
+		info.EffectivePolicy = info.SubdomainPolicy
+	}
+
+	info.Valid = len(info.Errors) == 0
+	if info.Valid {
+		info.Details = fmt.Sprintf("DMARC record found with policy: %s", info.Policy)
+		if info.Inherited {
+			info.Details = fmt.Sprintf("DMARC record inherited from %s with policy: %s", orgDomain, info.EffectivePolicy)
+		}
+	} else {
+		info.Details = fmt.Sprintf("DMARC record invalid: %s", info.Errors[0])
+	}
+
+	return info
+}
+
+// -----------------------------
+// DMARC record parsing (RFC 7489)
+// -----------------------------
+
+// dmarcKnownTags are the tags defined by RFC 7489 section 6.3
+var dmarcKnownTags = []string{"v", "p", "sp", "pct", "rua", "ruf", "adkim", "aspf", "fo", "rf", "ri"}
+
+// dmarcSizeRegex matches the optional report size limit of a DMARC URI
+var dmarcSizeRegex = regexp.MustCompile(`^(\d+)([kmgt]?)$`)
+
+// DMARCReportURI represents a rua or ruf reporting destination
+type DMARCReportURI struct {
+	URI        string `json:"uri"`
+	Address    string `json:"address,omitempty"`
+	Domain     string `json:"domain,omitempty"`
+	SizeLimit  string `json:"size_limit,omitempty"`
+	External   bool   `json:"external"`
+	Authorized bool   `json:"authorized"`
+	Error      string `json:"error,omitempty"`
+}
+
+// lookupDMARCRecords returns the v=DMARC1 records at _dmarc.domain
+func lookupDMARCRecords(domain string) ([]string, error) {
+	txtRecords, err := net.LookupTXT("_dmarc." + domain)
+	if err != nil {
+		if isVoidLookup(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []string
+	for _, txt := range txtRecords {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(txt)), "v=dmarc1") {
+			records = append(records, txt)
+		}
+	}
+	return records, nil
+}
+
+// parseDMARCURIs parses a comma separated rua/ruf list
+func parseDMARCURIs(value string) ([]DMARCReportURI, []string) {
+	var uris []DMARCReportURI
+	var errs []string
+	for _, raw := range strings.Split(value, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		uri := DMARCReportURI{URI: raw}
+		target := raw
+		if i := strings.LastIndex(raw, "!"); i >= 0 {
+			target = raw[:i]
+			uri.SizeLimit = raw[i+1:]
+			if !dmarcSizeRegex.MatchString(strings.ToLower(uri.SizeLimit)) {
+				uri.Error = fmt.Sprintf("invalid size limit %q", uri.SizeLimit)
+			}
+		}
+		u, err := url.Parse(target)
+		switch {
+		case err != nil || u.Scheme == "":
+			uri.Error = "not a URI"
+		case strings.ToLower(u.Scheme) != "mailto":
+			uri.Error = fmt.Sprintf("unsupported scheme %s; receivers only send to mailto", u.Scheme)
+		default:
+			uri.Address = u.Opaque
+			if at := strings.LastIndex(uri.Address, "@"); at > 0 {
+				uri.Domain = strings.ToLower(uri.Address[at+1:])
+			} else {
+				uri.Error = "mailto address has no domain"
+			}
+		}
+		if uri.Error != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", raw, uri.Error))
+		}
+		uris = append(uris, uri)
+	}
+	return uris, errs
+}
+
+// parseDMARCRecord parses and validates the tags of a DMARC record
+func parseDMARCRecord(info *DMARCInfo, record string) {
+	tags, order, issues := parseTagList(record)
+	info.Tags = tags
+	info.Errors = append(info.Errors, issues...)
+
+	if len(order) == 0 || order[0] != "v" || tags["v"] != "DMARC1" {
+		info.Errors = append(info.Errors, "v=DMARC1 must be the first tag")
+	}
+	for _, name := range order {
+		if !contains(dmarcKnownTags, name) {
+			info.Warnings = append(info.Warnings, fmt.Sprintf("Unknown tag %s= is ignored", name))
+		}
+	}
+
+	policyValues := []string{"none", "quarantine", "reject"}
+	if p, ok := tags["p"]; !ok {
+		info.Errors = append(info.Errors, "Required p= tag is missing")
+	} else if !contains(policyValues, strings.ToLower(p)) {
+		info.Errors = append(info.Errors, fmt.Sprintf("Invalid policy p=%s", p))
+	} else {
+		info.Policy = strings.ToLower(p)
+	}
+	if sp, ok := tags["sp"]; ok {
+		if !contains(policyValues, strings.ToLower(sp)) {
+			info.Errors = append(info.Errors, fmt.Sprintf("Invalid subdomain policy sp=%s", sp))
+		} else {
+			info.SubdomainPolicy = strings.ToLower(sp)
+		}
+	}
+
+	info.Percentage = 100
+	if pct, ok := tags["pct"]; ok {
+		n, err := strconv.Atoi(pct)
+		if err != nil || n < 0 || n > 100 {
+			info.Errors = append(info.Errors, fmt.Sprintf("Invalid pct=%s; must be 0-100", pct))
+		} else {
+			info.Percentage = n
+		}
+	}
+
+	info.ADKIM, info.ASPF = "r", "r"
+	for _, tag := range []string{"adkim", "aspf"} {
+		v, ok := tags[tag]
+		if !ok {
+			continue
+		}
+		v = strings.ToLower(v)
+		if v != "r" && v != "s" {
+			info.Errors = append(info.Errors, fmt.Sprintf("Invalid %s=%s; must be r or s", tag, v))
+			continue
+		}
+		if tag == "adkim" {
+			info.ADKIM = v
+		} else {
+			info.ASPF = v
+		}
+	}
+
+	info.FailureOptions = "0"
+	if fo, ok := tags["fo"]; ok {
+		for _, opt := range strings.Split(fo, ":") {
+			if !contains([]string{"0", "1", "d", "s"}, strings.ToLower(opt)) {
+				info.Errors = append(info.Errors, fmt.Sprintf("Invalid fo option %q", opt))
+			}
+		}
+		info.FailureOptions = fo
+	}
+	info.ReportFormat = "afrf"
+	if rf, ok := tags["rf"]; ok {
+		for _, format := range strings.Split(rf, ":") {
+			if !strings.EqualFold(format, "afrf") {
+				info.Errors = append(info.Errors, fmt.Sprintf("Invalid rf format %q", format))
+			}
+		}
+		info.ReportFormat = rf
+	}
+	info.ReportInterval = 86400
+	if ri, ok := tags["ri"]; ok {
+		n, err := strconv.ParseUint(ri, 10, 32)
+		if err != nil {
+			info.Errors = append(info.Errors, fmt.Sprintf("Invalid ri=%s", ri))
+		} else {
+			info.ReportInterval = int(n)
+		}
+	}
+
+	var errs []string
+	if rua, ok := tags["rua"]; ok {
+		info.RUA, errs = parseDMARCURIs(rua)
+		info.Errors = append(info.Errors, errs...)
+	}
+	if ruf, ok := tags["ruf"]; ok {
+		info.RUF, errs = parseDMARCURIs(ruf)
+		info.Errors = append(info.Errors, errs...)
+	}
+
+	// Advice that does not invalidate the record
+	switch info.Policy {
+	case "none":
+		info.Warnings = append(info.Warnings, "p=none only monitors; spoofed mail is still delivered")
+	case "quarantine", "reject":
+		if info.Percentage < 100 {
+			info.Warnings = append(info.Warnings, fmt.Sprintf("pct=%d applies the policy to only part of failing mail", info.Percentage))
+		}
+	}
+	if info.SubdomainPolicy == "none" && info.Policy != "none" {
+		info.Warnings = append(info.Warnings, "sp=none leaves subdomains unprotected")
+	}
+	if len(info.RUA) == 0 {
+		info.Warnings = append(info.Warnings, "No rua= address; aggregate reports are not received")
+	}
+}
+
+// verifyDMARCDestinations checks that report domains outside the policy's
+// organizational domain authorize it (RFC 7489 section 7.1)
+func verifyDMARCDestinations(info *DMARCInfo, uris []DMARCReportURI) {
+	for i := range uris {
+		uri := &uris[i]
+		if uri.Domain == "" {
+			continue
+		}
+		reportOrg, err := publicsuffix.EffectiveTLDPlusOne(uri.Domain)
+		if err != nil {
+			reportOrg = uri.Domain
+		}
+		if reportOrg == info.OrgDomain {
+			uri.Authorized = true
+			continue
+		}
+
+		uri.External = true
+		records, err := net.LookupTXT(info.Domain + "._report._dmarc." + uri.Domain)
+		if err == nil {
+			for _, txt := range records {
+				if strings.HasPrefix(strings.ToLower(strings.TrimSpace(txt)), "v=dmarc1") {
+					uri.Authorized = true
+				}
+			}
+		}
+		if !uri.Authorized {
+			uri.Error = fmt.Sprintf("%s does not authorize reports for %s (%s._report._dmarc.%s)", uri.Domain, info.Domain, info.Domain, uri.Domain)
+			info.Warnings = append(info.Warnings, fmt.Sprintf("External report destination %s is not authorized; receivers will not send reports there", uri.Address))
+		}
+	}
+}
+
+// handleDMARC handles DMARC record analysis
+func handleDMARC(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/dmarc", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	dmarcInfo := checker.CheckDMARC(domain)
+	if ttl, ok := routeTTL["/api/v1/dmarc"]; ok {
+		apiCache.Set(key, dmarcInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    dmarcInfo,
+	})
+}
+
+// -----------------------------
+// DMARC aggregate report ingestion
+// -----------------------------
+
+const (
+	maxDMARCUploadBytes    = 10 << 20
+	maxDMARCReportXMLBytes = 32 << 20 // decompressed, across all files of an upload
+	maxStoredDMARCReports  = 5000
+	maxDMARCTrendSources   = 100
+)
+
+// dmarcFeedback mirrors the aggregate report schema of RFC 7489 Appendix C
+type dmarcFeedback struct {
+	XMLName  xml.Name `xml:"feedback"`
+	Metadata struct {
+		OrgName   string `xml:"org_name"`
+		Email     string `xml:"email"`
+		ReportID  string `xml:"report_id"`
+		DateRange struct {
+			Begin int64 `xml:"begin"`
+			End   int64 `xml:"end"`
+		} `xml:"date_range"`
+	} `xml:"report_metadata"`
+	Policy  DMARCPublishedPolicy `xml:"policy_published"`
+	Records []struct {
+		Row struct {
+			SourceIP        string `xml:"source_ip"`
+			Count           int    `xml:"count"`
+			PolicyEvaluated struct {
+				Disposition string `xml:"disposition"`
+				DKIM        string `xml:"dkim"`
+				SPF         string `xml:"spf"`
+				Reasons     []struct {
+					Type    string `xml:"type"`
+					Comment string `xml:"comment"`
+				} `xml:"reason"`
+			} `xml:"policy_evaluated"`
+		} `xml:"row"`
+		Identifiers struct {
+			HeaderFrom   string `xml:"header_from"`
+			EnvelopeFrom string `xml:"envelope_from"`
+		} `xml:"identifiers"`
+		AuthResults struct {
+			DKIM []struct {
+				Domain string `xml:"domain"`
+				Result string `xml:"result"`
+			} `xml:"dkim"`
+			SPF []struct {
+				Domain string `xml:"domain"`
+				Result string `xml:"result"`
+			} `xml:"spf"`
+		} `xml:"auth_results"`
+	} `xml:"record"`
+}
+
+// DMARCPublishedPolicy is the policy the reporter saw for the domain
+type DMARCPublishedPolicy struct {
+	Domain string `xml:"domain" json:"domain"`
+	ADKIM  string `xml:"adkim" json:"adkim,omitempty"`
+	ASPF   string `xml:"aspf" json:"aspf,omitempty"`
+	P      string `xml:"p" json:"p,omitempty"`
+	SP     string `xml:"sp" json:"sp,omitempty"`
+	Pct    int    `xml:"pct" json:"pct,omitempty"`
+}
+
+// DMARCReportSource aggregates the rows of one sending IP
+type DMARCReportSource struct {
+	SourceIP     string         `json:"source_ip"`
+	PTR          string         `json:"ptr,omitempty"`
+	ASN          uint           `json:"asn,omitempty"`
+	ASOrg        string         `json:"as_org,omitempty"`
+	Country      string         `json:"country,omitempty"`
+	Messages     int            `json:"messages"`
+	DMARCPass    int            `json:"dmarc_pass"`
+	DKIMAligned  int            `json:"dkim_aligned"`
+	SPFAligned   int            `json:"spf_aligned"`
+	Dispositions map[string]int `json:"dispositions"`
+	HeaderFrom   []string       `json:"header_from,omitempty"`
+	DKIMDomains  []string       `json:"dkim_domains,omitempty"`
+	SPFDomains   []string       `json:"spf_domains,omitempty"`
+	Overrides    []string       `json:"overrides,omitempty"`
+}
+
+// DMARCReportSummary totals messages of a report or trend window
+type DMARCReportSummary struct {
+	Messages     int            `json:"messages"`
+	DMARCPass    int            `json:"dmarc_pass"`
+	DKIMAligned  int            `json:"dkim_aligned"`
+	SPFAligned   int            `json:"spf_aligned"`
+	PassRate     float64        `json:"pass_rate"`
+	Dispositions map[string]int `json:"dispositions"`
+}
+
+// DMARCAggregateReport is a parsed and enriched aggregate report
+type DMARCAggregateReport struct {
+	ID         string               `json:"id"`
+	OrgName    string               `json:"org_name"`
+	Email      string               `json:"email,omitempty"`
+	ReportID   string               `json:"report_id"`
+	Begin      time.Time            `json:"begin"`
+	End        time.Time            `json:"end"`
+	Policy     DMARCPublishedPolicy `json:"policy_published"`
+	Summary    DMARCReportSummary   `json:"summary"`
+	Sources    []DMARCReportSource  `json:"sources"`
+	ReceivedAt time.Time            `json:"received_at"`
+	Duplicate  bool                 `json:"duplicate,omitempty"`
+}
+
+// DMARCUploadResult is returned by the report upload endpoint
+type DMARCUploadResult struct {
+	Reports []DMARCAggregateReport `json:"reports"`
+	Errors  []string               `json:"errors,omitempty"`
+}
+
+// DMARCTrendPoint totals the reports whose range begins on one UTC day
+type DMARCTrendPoint struct {
+	Date string `json:"date"`
+	DMARCReportSummary
+}
+
+// DMARCTrendInfo summarizes the stored reports of a domain
+type DMARCTrendInfo struct {
+	Domain    string              `json:"domain"`
+	Days      int                 `json:"days"`
+	Reports   int                 `json:"reports"`
+	Reporters map[string]int      `json:"reporters"`
+	Summary   DMARCReportSummary  `json:"summary"`
+	Daily     []DMARCTrendPoint   `json:"daily"`
+	Sources   []DMARCReportSource `json:"sources"`
+}
+
+// dmarcReportStore keeps aggregate reports in memory, persisted to
+// DMARC_REPORT_STORE as JSON when that variable is set
+type dmarcReportStore struct {
+	mu      sync.RWMutex
+	once    sync.Once
+	path    string
+	reports map[string]*DMARCAggregateReport
+}
+
+var dmarcReports = &dmarcReportStore{}
+
+// load reads the persisted reports on first use
+func (s *dmarcReportStore) load() {
+	s.once.Do(func() {
+		s.reports = make(map[string]*DMARCAggregateReport)
+		s.path = os.Getenv("DMARC_REPORT_STORE")
+		if s.path == "" {
+			return
+		}
+		data, err := os.ReadFile(s.path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				fmt.Printf("Warning: failed to read DMARC report store %s: %v\n", s.path, err)
+			}
+			return
+		}
+		var reports []*DMARCAggregateReport
+		if err := json.Unmarshal(data, &reports); err != nil {
+			fmt.Printf("Warning: failed to parse DMARC report store %s: %v\n", s.path, err)
+			return
+		}
+		for _, r := range reports {
+			s.reports[r.ID] = r
+		}
+	})
+}
+
+// add stores reports, skipping ones already ingested, and evicts the oldest
+// beyond maxStoredDMARCReports
+func (s *dmarcReportStore) add(reports []*DMARCAggregateReport) {
+	s.load()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range reports {
+		if _, ok := s.reports[r.ID]; ok {
+			r.Duplicate = true
+			continue
+		}
+		s.reports[r.ID] = r
+	}
+	all := s.sortedLocked()
+	for len(all) > maxStoredDMARCReports {
+		delete(s.reports, all[0].ID)
+		all = all[1:]
+	}
+
+	if s.path == "" {
+		return
+	}
+	data, err := json.Marshal(all)
+	if err == nil {
+		tmp := s.path + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o600); err == nil {
+			err = os.Rename(tmp, s.path)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to persist DMARC report store %s: %v\n", s.path, err)
+	}
+}
+
+// sortedLocked returns stored reports ordered by range start
+func (s *dmarcReportStore) sortedLocked() []*DMARCAggregateReport {
+	all := make([]*DMARCAggregateReport, 0, len(s.reports))
+	for _, r := range s.reports {
+		all = append(all, r)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Begin.Before(all[j].Begin) })
+	return all
+}
+
+// forDomain returns reports for a policy domain whose range starts after since
+func (s *dmarcReportStore) forDomain(domain string, since time.Time) []*DMARCAggregateReport {
+	s.load()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []*DMARCAggregateReport
+	for _, r := range s.sortedLocked() {
+		if strings.EqualFold(r.Policy.Domain, domain) && !r.Begin.Before(since) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// isGzip and isZip detect compressed uploads by their magic bytes
+func isGzip(data []byte) bool { return bytes.HasPrefix(data, []byte{0x1f, 0x8b}) }
+func isZip(data []byte) bool  { return bytes.HasPrefix(data, []byte("PK\x03\x04")) }
+
+// extractDMARCDocuments returns the XML documents of a plain, gzip or zip
+// upload; budget bounds the decompressed size to defuse compression bombs
+func extractDMARCDocuments(name string, data []byte, budget *int64) ([][]byte, []string) {
+	readLimited := func(r io.Reader) ([]byte, error) {
+		out, err := io.ReadAll(io.LimitReader(r, *budget+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(out)) > *budget {
+			return nil, fmt.Errorf("decompressed size exceeds %d bytes", maxDMARCReportXMLBytes)
+		}
+		*budget -= int64(len(out))
+		return out, nil
+	}
+
+	switch {
+	case isGzip(data):
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, []string{fmt.Sprintf("%s: invalid gzip: %v", name, err)}
+		}
+		defer zr.Close()
+		xmlData, err := readLimited(zr)
+		if err != nil {
+			return nil, []string{fmt.Sprintf("%s: %v", name, err)}
+		}
+		return [][]byte{xmlData}, nil
+	case isZip(data):
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, []string{fmt.Sprintf("%s: invalid zip: %v", name, err)}
+		}
+		var docs [][]byte
+		var errs []string
+		for _, f := range zr.File {
+			lower := strings.ToLower(f.Name)
+			if f.FileInfo().IsDir() || !(strings.HasSuffix(lower, ".xml") || strings.HasSuffix(lower, ".gz")) {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s/%s: %v", name, f.Name, err))
+				continue
+			}
+			content, err := readLimited(rc)
+			rc.Close()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s/%s: %v", name, f.Name, err))
+				continue
+			}
+			// Archives occasionally wrap gzip files; do not recurse into zips
+			if isGzip(content) {
+				d, e := extractDMARCDocuments(name+"/"+f.Name, content, budget)
+				docs = append(docs, d...)
+				errs = append(errs, e...)
+				continue
+			}
+			docs = append(docs, content)
+		}
+		return docs, errs
+	default:
+		return [][]byte{data}, nil
+	}
+}
+
+// parseDMARCAggregate parses one aggregate report document
+func parseDMARCAggregate(data []byte) (*DMARCAggregateReport, error) {
+	var fb dmarcFeedback
+	if err := xml.Unmarshal(data, &fb); err != nil {
+		return nil, fmt.Errorf("invalid aggregate report XML: %v", err)
+	}
+	if fb.Metadata.ReportID == "" || fb.Policy.Domain == "" {
+		return nil, fmt.Errorf("aggregate report is missing report_id or policy_published domain")
+	}
+
+	report := &DMARCAggregateReport{
+		ID:         strings.ToLower(fb.Metadata.OrgName) + "|" + fb.Metadata.ReportID,
+		OrgName:    fb.Metadata.OrgName,
+		Email:      fb.Metadata.Email,
+		ReportID:   fb.Metadata.ReportID,
+		Begin:      time.Unix(fb.Metadata.DateRange.Begin, 0).UTC(),
+		End:        time.Unix(fb.Metadata.DateRange.End, 0).UTC(),
+		Policy:     fb.Policy,
+		ReceivedAt: time.Now().UTC(),
+	}
+	report.Policy.Domain = strings.ToLower(strings.TrimSuffix(report.Policy.Domain, "."))
+
+	bySource := make(map[string]*DMARCReportSource)
+	var order []string
+	for _, rec := range fb.Records {
+		ip := strings.TrimSpace(rec.Row.SourceIP)
+		src, ok := bySource[ip]
+		if !ok {
+			src = &DMARCReportSource{SourceIP: ip, Dispositions: make(map[string]int)}
+			bySource[ip] = src
+			order = append(order, ip)
+		}
+
+		count := rec.Row.Count
+		if count < 0 {
+			return nil, fmt.Errorf("record for %s has a negative count", ip)
+		}
+		eval := rec.Row.PolicyEvaluated
+		dkimPass := strings.EqualFold(eval.DKIM, "pass")
+		spfPass := strings.EqualFold(eval.SPF, "pass")
+		src.Messages += count
+		if dkimPass {
+			src.DKIMAligned += count
+		}
+		if spfPass {
+			src.SPFAligned += count
+		}
+		if dkimPass || spfPass {
+			src.DMARCPass += count
+		}
+		src.Dispositions[strings.ToLower(eval.Disposition)] += count
+		for _, reason := range eval.Reasons {
+			if !contains(src.Overrides, reason.Type) {
+				src.Overrides = append(src.Overrides, reason.Type)
+			}
+		}
+
+		if from := strings.ToLower(rec.Identifiers.HeaderFrom); from != "" && !contains(src.HeaderFrom, from) {
+			src.HeaderFrom = append(src.HeaderFrom, from)
+		}
+		for _, d := range rec.AuthResults.DKIM {
+			entry := fmt.Sprintf("%s=%s", strings.ToLower(d.Domain), d.Result)
+			if !contains(src.DKIMDomains, entry) {
+				src.DKIMDomains = append(src.DKIMDomains, entry)
+			}
+		}
+		for _, s := range rec.AuthResults.SPF {
+			entry := fmt.Sprintf("%s=%s", strings.ToLower(s.Domain), s.Result)
+			if !contains(src.SPFDomains, entry) {
+				src.SPFDomains = append(src.SPFDomains, entry)
+			}
+		}
+	}
+
+	for _, ip := range order {
+		report.Sources = append(report.Sources, *bySource[ip])
+	}
+	sort.SliceStable(report.Sources, func(i, j int) bool { return report.Sources[i].Messages > report.Sources[j].Messages })
+	report.Summary = summarizeDMARCSources(report.Sources)
+	return report, nil
+}
+
+// summarizeDMARCSources totals per-source counts
+func summarizeDMARCSources(sources []DMARCReportSource) DMARCReportSummary {
+	sum := DMARCReportSummary{Dispositions: make(map[string]int)}
+	for _, src := range sources {
+		sum.Messages += src.Messages
+		sum.DMARCPass += src.DMARCPass
+		sum.DKIMAligned += src.DKIMAligned
+		sum.SPFAligned += src.SPFAligned
+		for d, n := range src.Dispositions {
+			sum.Dispositions[d] += n
+		}
+	}
+	if sum.Messages > 0 {
+		sum.PassRate = math.Round(float64(sum.DMARCPass)/float64(sum.Messages)*10000) / 100
+	}
+	return sum
+}
+
+// enrichDMARCSources adds rDNS, ASN and country to every source IP
+func enrichDMARCSources(reports []*DMARCAggregateReport) {
+	type enrichment struct {
+		ptr, org, country string
+		asn               uint
+	}
+	seen := make(map[string]*enrichment)
+	for _, r := range reports {
+		for _, src := range r.Sources {
+			if net.ParseIP(src.SourceIP) != nil {
+				seen[src.SourceIP] = &enrichment{}
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 16)
+	for ip, e := range seen {
+		wg.Add(1)
+		go func(ip string, e *enrichment) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+			if names, err := net.DefaultResolver.LookupAddr(ctx, ip); err == nil && len(names) > 0 {
+				e.ptr = strings.TrimSuffix(names[0], ".")
+			}
+			var organization string
+			e.country, _, _, _, organization, _ = lookupGeoIP(ip)
+			e.asn, e.org = lookupASN(ip)
+			if e.org == "" {
+				e.org = organization
+			}
+		}(ip, e)
+	}
+	wg.Wait()
+
+	for _, r := range reports {
+		for i := range r.Sources {
+			if e, ok := seen[r.Sources[i].SourceIP]; ok {
+				r.Sources[i].PTR, r.Sources[i].ASN, r.Sources[i].ASOrg, r.Sources[i].Country = e.ptr, e.asn, e.org, e.country
+			}
+		}
+	}
+}
+
+// IngestDMARCReports parses, enriches and stores the reports of an upload
+func IngestDMARCReports(files map[string][]byte) DMARCUploadResult {
+	var result DMARCUploadResult
+	var reports []*DMARCAggregateReport
+	budget := int64(maxDMARCReportXMLBytes)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		docs, errs := extractDMARCDocuments(name, files[name], &budget)
+		result.Errors = append(result.Errors, errs...)
+		for _, doc := range docs {
+			report, err := parseDMARCAggregate(doc)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			reports = append(reports, report)
+		}
+	}
+
+	enrichDMARCSources(reports)
+	dmarcReports.add(reports)
+	for _, r := range reports {
+		result.Reports = append(result.Reports, *r)
+	}
+	return result
+}
+
+// DMARCTrends aggregates stored reports of a domain over the last days
+func DMARCTrends(domain string, days int) DMARCTrendInfo {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -days+1)
+	info := DMARCTrendInfo{Domain: domain, Days: days, Reporters: make(map[string]int)}
+
+	daily := make(map[string][]DMARCReportSource)
+	bySource := make(map[string]*DMARCReportSource)
+	var all []DMARCReportSource
+	for _, r := range dmarcReports.forDomain(domain, since) {
+		info.Reports++
+		info.Reporters[r.OrgName]++
+		day := r.Begin.Format("2006-01-02")
+		daily[day] = append(daily[day], r.Sources...)
+		all = append(all, r.Sources...)
+
+		for _, src := range r.Sources {
+			merged, ok := bySource[src.SourceIP]
+			if !ok {
+				merged = &DMARCReportSource{
+					SourceIP: src.SourceIP, PTR: src.PTR, ASN: src.ASN, ASOrg: src.ASOrg, Country: src.Country,
+					Dispositions: make(map[string]int),
+				}
+				bySource[src.SourceIP] = merged
+			}
+			merged.Messages += src.Messages
+			merged.DMARCPass += src.DMARCPass
+			merged.DKIMAligned += src.DKIMAligned
+			merged.SPFAligned += src.SPFAligned
+			for d, n := range src.Dispositions {
+				merged.Dispositions[d] += n
+			}
+			for _, v := range src.HeaderFrom {
+				if !contains(merged.HeaderFrom, v) {
+					merged.HeaderFrom = append(merged.HeaderFrom, v)
+				}
+			}
+		}
+	}
+
+	info.Summary = summarizeDMARCSources(all)
+	for d := since; !d.After(time.Now().UTC()); d = d.AddDate(0, 0, 1) {
+		day := d.Format("2006-01-02")
+		info.Daily = append(info.Daily, DMARCTrendPoint{Date: day, DMARCReportSummary: summarizeDMARCSources(daily[day])})
+	}
+	for _, src := range bySource {
+		info.Sources = append(info.Sources, *src)
+	}
+	sort.Slice(info.Sources, func(i, j int) bool {
+		if info.Sources[i].Messages != info.Sources[j].Messages {
+			return info.Sources[i].Messages > info.Sources[j].Messages
+		}
+		return info.Sources[i].SourceIP < info.Sources[j].SourceIP
+	})
+	if len(info.Sources) > maxDMARCTrendSources {
+		info.Sources = info.Sources[:maxDMARCTrendSources]
+	}
+	return info
+}
+
+// handleDMARCReportUpload accepts aggregate reports as a raw body (XML, gzip
+// or zip) or as multipart files
+func handleDMARCReportUpload(c *gin.Context) {
+	// Uploads write into a shared, persisted store, so they need the operator's token
+	token := os.Getenv("DMARC_UPLOAD_TOKEN")
+	if token == "" {
+		c.JSON(http.StatusForbidden, APIResponse{
+			Success: false,
+			Error:   "DMARC report upload is disabled; set DMARC_UPLOAD_TOKEN to enable it",
+		})
+		return
+	}
+	given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		c.JSON(http.StatusUnauthorized, APIResponse{
+			Success: false,
+			Error:   "A valid upload token is required (Authorization: Bearer <token>)",
+		})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxDMARCUploadBytes)
+
+	files := make(map[string][]byte)
+	var uploadErrors []string
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		form, err := c.MultipartForm()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid multipart body: %v", err),
+			})
+			return
+		}
+		for _, headers := range form.File {
+			for _, fh := range headers {
+				// Several parts may share a file name; keep each of them
+				name := fh.Filename
+				for n := 2; files[name] != nil; n++ {
+					name = fmt.Sprintf("%s (%d)", fh.Filename, n)
+				}
+				f, err := fh.Open()
+				if err != nil {
+					uploadErrors = append(uploadErrors, fmt.Sprintf("%s: %v", name, err))
+					continue
+				}
+				data, err := io.ReadAll(f)
+				f.Close()
+				if err != nil {
+					uploadErrors = append(uploadErrors, fmt.Sprintf("%s: %v", name, err))
+					continue
+				}
+				files[name] = data
+			}
+		}
+	} else {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to read request body: %v", err),
+			})
+			return
+		}
+		if len(body) > 0 {
+			files["body"] = body
+		}
+	}
+
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Data:    DMARCUploadResult{Errors: uploadErrors},
+			Error:   "No report uploaded",
+		})
+		return
+	}
+
+	result := IngestDMARCReports(files)
+	result.Errors = append(uploadErrors, result.Errors...)
+	if len(result.Reports) == 0 {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Data:    result,
+			Error:   "No valid DMARC aggregate reports found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// handleDMARCReportTrends returns trends over the stored reports of a domain
+func handleDMARCReportTrends(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	days := 30
+	if v := c.Query("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "days must be between 1 and 365",
+			})
+			return
+		}
+		days = n
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    DMARCTrends(domain, days),
+	})
+}
+
+// CheckBIMI checks BIMI (Brand Indicators for Message Identification) record,
+// its logo and Verified Mark Certificate, and the DMARC enforcement it relies on
+func (nc *NetChecker) CheckBIMI(domain string) BIMIInfo {
+	return nc.checkBIMI(domain, nil)
+}
+
+// checkBIMI runs CheckBIMI, reusing the domain's DMARC result when the caller
+// already has it
+func (nc *NetChecker) checkBIMI(domain string, dmarc *DMARCInfo) BIMIInfo {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	info := BIMIInfo{Domain: domain}
+
+	// BIMI record is in default._bimi subdomain, falling back to the organizational domain
+	records, err := lookupBIMIRecords(domain)
+	if err == nil && len(records) == 0 {
+		if orgDomain, oerr := publicsuffix.EffectiveTLDPlusOne(domain); oerr == nil && orgDomain != domain {
+			records, err = lookupBIMIRecords(orgDomain)
+			if len(records) > 0 {
+				info.Domain = orgDomain
+			}
+		}
+	}
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to lookup BIMI record: %v", err)
+	}
+	if len(records) == 0 {
+		info.Details = "No BIMI record found"
+		return info
+	}
+
+	info.Configured = true
+	info.Record = records[0]
+	if len(records) > 1 {
+		info.Errors = append(info.Errors, fmt.Sprintf("%d BIMI records published; receivers ignore all of them", len(records)))
+	}
+	parseBIMIRecord(&info, records[0])
+
+	// BIMI requires an enforcing DMARC policy on the whole organizational domain
+	if dmarc == nil {
+		result := nc.CheckDMARC(domain)
+		dmarc = &result
+	}
+	info.DMARCPolicy = dmarc.EffectivePolicy
+	info.DMARCEnforced = dmarc.Valid && (dmarc.EffectivePolicy == "quarantine" || dmarc.EffectivePolicy == "reject") &&
+		dmarc.Percentage == 100 && dmarc.SubdomainPolicy != "none"
+
+	// A declination record (empty l= and a=) is valid regardless of DMARC; judge it
+	// from the raw tags so an l= that failed validation is not taken as one
+	logoTag, hasLogo := info.Tags["l"]
+	declined := hasLogo && logoTag == "" && info.Tags["a"] == ""
+	if !info.DMARCEnforced && !declined {
+		info.Errors = append(info.Errors, "DMARC is not at enforcement (p=quarantine or p=reject with pct=100 and no sp=none is required)")
+	}
+	var logoData []byte
+	if info.LogoURL != "" {
+		info.Logo, logoData = nc.checkBIMILogo(info.LogoURL)
+		if info.Logo.Error != "" {
+			info.Errors = append(info.Errors, info.Logo.Error)
+		} else if !info.Logo.Valid {
+			info.Errors = append(info.Errors, "Logo does not conform to SVG Tiny PS")
+		}
+	}
+	if info.AuthorityURL != "" {
+		info.Certificate = nc.checkBIMICertificate(info.AuthorityURL, info.Domain, logoData)
+		if info.Certificate.Error != "" {
+			info.Errors = append(info.Errors, info.Certificate.Error)
+		} else if !info.Certificate.Valid {
+			info.Errors = append(info.Errors, "Verified Mark Certificate failed validation")
+		}
+	} else if !declined {
+		info.Warnings = append(info.Warnings, "No a= certificate; most mailbox providers only display logos backed by a VMC")
+	}
+
+	info.Valid = len(info.Errors) == 0
+	switch {
+	case declined:
+		info.Details = "BIMI record declines to publish a logo"
+	case info.Valid:
+		info.Details = fmt.Sprintf("BIMI record found with logo: %s", info.LogoURL)
+	default:
+		info.Details = fmt.Sprintf("BIMI record invalid: %s", info.Errors[0])
+	}
+
+	return info
+}
+
+// -----------------------------
+// BIMI logo and Verified Mark Certificate validation
+// -----------------------------
+
+const maxBIMILogoBytes = 32 << 10 // recommended maximum size of a BIMI logo
+
+// bimiKnownTags are the tags of a BIMI assertion record
+var bimiKnownTags = []string{"v", "l", "a", "avp"}
+
+var (
+	oidLogotypeExtension = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 12}
+	oidBIMIExtKeyUsage   = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 31}
+	oidBIMIMarkType      = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 53087, 1, 13}
+)
+
+// bimiHashAlgorithms maps logotype hash OIDs to their hash functions
+var bimiHashAlgorithms = map[string]struct {
+	name string
+	hash func([]byte) []byte
+}{
+	"1.3.14.3.2.26":          {"sha1", func(b []byte) []byte { s := sha1.Sum(b); return s[:] }},
+	"2.16.840.1.101.3.4.2.1": {"sha256", func(b []byte) []byte { s := sha256.Sum256(b); return s[:] }},
+	"2.16.840.1.101.3.4.2.2": {"sha384", func(b []byte) []byte { s := sha512.Sum384(b); return s[:] }},
+	"2.16.840.1.101.3.4.2.3": {"sha512", func(b []byte) []byte { s := sha512.Sum512(b); return s[:] }},
+}
+
+// svgTinyPSForbidden lists elements the SVG Tiny Portable/Secure profile excludes
+var svgTinyPSForbidden = []string{
+	"script", "foreignobject", "image", "video", "audio", "animation", "animate",
+	"animatecolor", "animatemotion", "animatetransform", "set", "handler", "listener",
+	"iframe",
+}
+
+// BIMILogoInfo represents the validation of the l= logo
+type BIMILogoInfo struct {
+	URL         string   `json:"url"`
+	ContentType string   `json:"content_type,omitempty"`
+	Size        int      `json:"size"`
+	Compressed  bool     `json:"compressed"`
+	SHA256      string   `json:"sha256,omitempty"`
+	BaseProfile string   `json:"base_profile,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	ViewBox     string   `json:"view_box,omitempty"`
+	Square      bool     `json:"square"`
+	Title       string   `json:"title,omitempty"`
+	Valid       bool     `json:"valid"`
+	Issues      []string `json:"issues,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// BIMICertificateInfo represents the validation of the a= Verified Mark Certificate
+type BIMICertificateInfo struct {
+	URL               string    `json:"url"`
+	Subject           string    `json:"subject,omitempty"`
+	Issuer            string    `json:"issuer,omitempty"`
+	NotBefore         time.Time `json:"not_before"`
+	NotAfter          time.Time `json:"not_after"`
+	DNSNames          []string  `json:"dns_names,omitempty"`
+	MarkType          string    `json:"mark_type,omitempty"`
+	ChainLength       int       `json:"chain_length"`
+	ChainValid        bool      `json:"chain_valid"`
+	BIMIUsage         bool      `json:"bimi_usage"`
+	DomainMatch       bool      `json:"domain_match"`
+	HasLogotype       bool      `json:"has_logotype"`
+	LogoHashAlgorithm string    `json:"logo_hash_algorithm,omitempty"`
+	LogoHashValid     bool      `json:"logo_hash_valid"`
+	LogoMatch         bool      `json:"logo_match"`
+	Valid             bool      `json:"valid"`
+	Issues            []string  `json:"issues,omitempty"`
+	Error             string    `json:"error,omitempty"`
+}
+
+// logotypeDetails is the LogotypeDetails structure of RFC 3709
+type logotypeDetails struct {
+	mediaType string
+	hashes    []logotypeHash
+	uris      []string
+}
+
+type logotypeHash struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Value     []byte
+}
+
+// lookupBIMIRecords returns the v=BIMI1 records at default._bimi.domain
+func lookupBIMIRecords(domain string) ([]string, error) {
+	txtRecords, err := net.LookupTXT("default._bimi." + domain)
+	if err != nil {
+		if isVoidLookup(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []string
+	for _, txt := range txtRecords {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(txt)), "v=bimi1") {
+			records = append(records, txt)
+		}
+	}
+	return records, nil
+}
+
+// parseBIMIRecord parses and validates the tags of a BIMI record
+func parseBIMIRecord(info *BIMIInfo, record string) {
+	tags, order, issues := parseTagList(record)
+	info.Tags = tags
+	info.Errors = append(info.Errors, issues...)
+
+	if len(order) == 0 || order[0] != "v" || !strings.EqualFold(tags["v"], "BIMI1") {
+		info.Errors = append(info.Errors, "v=BIMI1 must be the first tag")
+	}
+	for _, name := range order {
+		if !contains(bimiKnownTags, name) {
+			info.Warnings = append(info.Warnings, fmt.Sprintf("Unknown tag %s= is ignored", name))
+		}
+	}
+
+	if _, ok := tags["l"]; !ok {
+		info.Errors = append(info.Errors, "Required l= tag is missing")
+	}
+	for _, tag := range []string{"l", "a"} {
+		value := tags[tag]
+		if value == "" {
+			continue
+		}
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			info.Errors = append(info.Errors, fmt.Sprintf("%s= must be an https URL", tag))
+			continue
+		}
+		if tag == "l" {
+			info.LogoURL = value
+		} else {
+			info.AuthorityURL = value
+		}
+	}
+	if avp, ok := tags["avp"]; ok && avp != "brand" && avp != "personal" {
+		info.Errors = append(info.Errors, fmt.Sprintf("Invalid avp=%s; must be brand or personal", avp))
+	}
+}
+
+// fetchBIMIResource downloads a logo or certificate from a public https URL
+func (nc *NetChecker) fetchBIMIResource(rawURL string, limit int64) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := resolveTarget(u.Hostname()); err != nil {
+		return nil, "", err
+	}
+
+	// The guarded dialer checks every connection, so a redirect or a changed DNS
+	// answer cannot reach an internal address; redirects must also stay on https
+	client := nc.publicHTTPClient()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirectHops {
+			return fmt.Errorf("stopped after %d redirects", maxRedirectHops)
+		}
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("redirect to non-https URL %s", req.URL)
+		}
+		if _, err := resolveTarget(req.URL.Hostname()); err != nil {
+			return err
+		}
+		return nil
+	}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	return body, resp.Header.Get("Content-Type"), err
+}
+
+// decompressSVG returns the SVG document of a plain or gzip-compressed logo
+func decompressSVG(data []byte) ([]byte, bool, error) {
+	if !isGzip(data) {
+		return data, false, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, true, err
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(io.LimitReader(zr, maxWellKnownBytes))
+	return out, true, err
+}
+
+// checkBIMILogo fetches the logo and validates it against SVG Tiny PS; the
+// decompressed SVG is returned for comparison with the certificate
+func (nc *NetChecker) checkBIMILogo(logoURL string) (*BIMILogoInfo, []byte) {
+	logo := &BIMILogoInfo{URL: logoURL}
+
+	raw, contentType, err := nc.fetchBIMIResource(logoURL, maxWellKnownBytes)
+	if err != nil {
+		logo.Error = fmt.Sprintf("Failed to fetch logo: %v", err)
+		return logo, nil
+	}
+	logo.ContentType = contentType
+	logo.Size = len(raw)
+	sum := sha256.Sum256(raw)
+	logo.SHA256 = hex.EncodeToString(sum[:])
+	if logo.Size > maxBIMILogoBytes {
+		logo.Issues = append(logo.Issues, fmt.Sprintf("Logo is %d bytes; keep it under %d", logo.Size, maxBIMILogoBytes))
+	}
+	if mt := mediaType(contentType); mt != "" && mt != "image/svg+xml" {
+		logo.Issues = append(logo.Issues, fmt.Sprintf("Served as %s instead of image/svg+xml", mt))
+	}
+
+	svg, compressed, err := decompressSVG(raw)
+	logo.Compressed = compressed
+	if err != nil {
+		logo.Error = fmt.Sprintf("Failed to decompress logo: %v", err)
+		return logo, nil
+	}
+
+	issues := validateSVGTinyPS(logo, svg)
+	logo.Issues = append(logo.Issues, issues...)
+	logo.Valid = len(issues) == 0
+	return logo, svg
+}
+
+// validateSVGTinyPS checks the structural rules of the SVG Tiny Portable/Secure
+// profile: tiny-ps root, a title, no scripts, animation or external references,
+// and a square viewBox
+func validateSVGTinyPS(logo *BIMILogoInfo, svg []byte) []string {
+	var issues []string
+	addIssue := func(issue string) {
+		if !contains(issues, issue) {
+			issues = append(issues, issue)
+		}
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(svg))
+	dec.Strict = true
+	depth := 0
+	inTitle := false
+	sawRoot := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return append(issues, fmt.Sprintf("Logo is not well-formed XML: %v", err))
+		}
+
+		switch t := tok.(type) {
+		case xml.Directive:
+			if strings.Contains(strings.ToUpper(string(t)), "ENTITY") {
+				addIssue("Logo declares XML entities")
+			}
+		case xml.StartElement:
+			depth++
+			name := strings.ToLower(t.Name.Local)
+			if depth == 1 {
+				sawRoot = true
+				if name != "svg" {
+					return append(issues, fmt.Sprintf("Root element is <%s>, not <svg>", t.Name.Local))
+				}
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "baseProfile":
+						logo.BaseProfile = attr.Value
+					case "version":
+						logo.Version = attr.Value
+					case "viewBox":
+						logo.ViewBox = attr.Value
+					case "x", "y":
+						addIssue("Root <svg> must not have x or y attributes")
+					}
+				}
+				if logo.BaseProfile != "tiny-ps" {
+					addIssue(`Root <svg> must declare baseProfile="tiny-ps"`)
+				}
+				if logo.Version != "1.2" {
+					addIssue(`Root <svg> must declare version="1.2"`)
+				}
+			}
+			if depth == 2 && name == "title" {
+				inTitle = true
+			}
+			if contains(svgTinyPSForbidden, name) {
+				addIssue(fmt.Sprintf("Element <%s> is not allowed in SVG Tiny PS", t.Name.Local))
+			}
+			for _, attr := range t.Attr {
+				attrName := strings.ToLower(attr.Name.Local)
+				if strings.HasPrefix(attrName, "on") {
+					addIssue(fmt.Sprintf("Event handler attribute %s is not allowed", attr.Name.Local))
+				}
+				if attrName == "href" && !strings.HasPrefix(strings.TrimSpace(attr.Value), "#") {
+					addIssue("External references are not allowed")
+				}
+				if strings.Contains(strings.ToLower(attr.Value), "url(") && !strings.Contains(strings.ReplaceAll(strings.ToLower(attr.Value), " ", ""), "url(#") {
+					addIssue("External references are not allowed")
+				}
+			}
+		case xml.EndElement:
+			depth--
+			inTitle = false
+		case xml.CharData:
+			if inTitle {
+				logo.Title += strings.TrimSpace(string(t))
+			}
+		}
+	}
+
+	if !sawRoot {
+		return append(issues, "Logo has no root element")
+	}
+	if logo.Title == "" {
+		addIssue("Logo must have a non-empty <title> element")
+	}
+	if fields := strings.Fields(strings.ReplaceAll(logo.ViewBox, ",", " ")); len(fields) == 4 {
+		w, werr := strconv.ParseFloat(fields[2], 64)
+		h, herr := strconv.ParseFloat(fields[3], 64)
+		logo.Square = werr == nil && herr == nil && w > 0 && w == h
+		if !logo.Square {
+			addIssue(fmt.Sprintf("viewBox %q is not square", logo.ViewBox))
+		}
+	} else {
+		addIssue("Root <svg> must have a viewBox")
+	}
+	return issues
+}
+
+// checkBIMICertificate fetches the VMC and verifies its chain, BIMI usage,
+// domain and the logotype extension against the published logo
+func (nc *NetChecker) checkBIMICertificate(certURL, domain string, logoSVG []byte) *BIMICertificateInfo {
+	cert := &BIMICertificateInfo{URL: certURL}
+
+	pemData, _, err := nc.fetchBIMIResource(certURL, maxWellKnownBytes)
+	if err != nil {
+		cert.Error = fmt.Sprintf("Failed to fetch certificate: %v", err)
+		return cert
+	}
+	var chain []*x509.Certificate
+	for rest := pemData; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			cert.Issues = append(cert.Issues, fmt.Sprintf("Unparseable certificate in PEM: %v", err))
+			continue
+		}
+		chain = append(chain, c)
+	}
+	if len(chain) == 0 {
+		cert.Error = "No certificates found in PEM"
+		return cert
+	}
+
+	leaf := chain[0]
+	cert.ChainLength = len(chain)
+	cert.Subject = leaf.Subject.String()
+	cert.Issuer = leaf.Issuer.String()
+	cert.NotBefore = leaf.NotBefore
+	cert.NotAfter = leaf.NotAfter
+	cert.DNSNames = leaf.DNSNames
+	for _, name := range leaf.Subject.Names {
+		if name.Type.Equal(oidBIMIMarkType) {
+			cert.MarkType = fmt.Sprint(name.Value)
+		}
+	}
+
+	// VMC roots are not in the system store; BIMI_TRUST_ROOTS adds a PEM bundle
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if path := os.Getenv("BIMI_TRUST_ROOTS"); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			roots.AppendCertsFromPEM(data)
+		} else {
+			cert.Issues = append(cert.Issues, fmt.Sprintf("Failed to read BIMI_TRUST_ROOTS: %v", err))
+		}
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		cert.Issues = append(cert.Issues, fmt.Sprintf("Chain verification failed: %v", err))
+	} else {
+		cert.ChainValid = true
+	}
+
+	for _, oid := range leaf.UnknownExtKeyUsage {
+		if oid.Equal(oidBIMIExtKeyUsage) {
+			cert.BIMIUsage = true
+		}
+	}
+	if !cert.BIMIUsage {
+		cert.Issues = append(cert.Issues, "Certificate lacks the BIMI extended key usage (1.3.6.1.5.5.7.3.31)")
+	}
+
+	for _, name := range leaf.DNSNames {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		if name == domain || name == "default._bimi."+domain {
+			cert.DomainMatch = true
+		}
+	}
+	if !cert.DomainMatch {
+		cert.Issues = append(cert.Issues, fmt.Sprintf("Certificate does not name %s", domain))
+	}
+
+	checkLogotype(cert, leaf, logoSVG)
+
+	cert.Valid = cert.ChainValid && cert.BIMIUsage && cert.DomainMatch && cert.HasLogotype && cert.LogoHashValid &&
+		(logoSVG == nil || cert.LogoMatch)
+	return cert
+}
+
+// checkLogotype verifies the embedded logo's hash and compares it with the
+// published logo
+func checkLogotype(cert *BIMICertificateInfo, leaf *x509.Certificate, logoSVG []byte) {
+	var extValue []byte
+	for _, ext := range leaf.Extensions {
+		if ext.Id.Equal(oidLogotypeExtension) {
+			extValue = ext.Value
+		}
+	}
+	if extValue == nil {
+		cert.Issues = append(cert.Issues, "Certificate has no logotype extension")
+		return
+	}
+	cert.HasLogotype = true
+
+	details := findLogotypeDetails(extValue, 0)
+	if len(details) == 0 {
+		cert.Issues = append(cert.Issues, "Logotype extension contains no image")
+		return
+	}
+	d := details[0]
+	if len(d.uris) == 0 || !strings.HasPrefix(d.uris[0], "data:") {
+		cert.Issues = append(cert.Issues, "Logotype image is not embedded as a data: URI")
+		return
+	}
+	imageData, err := decodeDataURI(d.uris[0])
+	if err != nil {
+		cert.Issues = append(cert.Issues, fmt.Sprintf("Logotype data URI is invalid: %v", err))
+		return
+	}
+
+	for _, h := range d.hashes {
+		alg, ok := bimiHashAlgorithms[h.Algorithm.Algorithm.String()]
+		if !ok {
+			continue
+		}
+		cert.LogoHashAlgorithm = alg.name
+		if bytes.Equal(alg.hash(imageData), h.Value) {
+			cert.LogoHashValid = true
+			break
+		}
+	}
+	if !cert.LogoHashValid {
+		cert.Issues = append(cert.Issues, "Embedded logo does not match the logotype hash")
+	}
+
+	if logoSVG == nil {
+		return
+	}
+	embedded, _, err := decompressSVG(imageData)
+	if err == nil && bytes.Equal(bytes.TrimSpace(embedded), bytes.TrimSpace(logoSVG)) {
+		cert.LogoMatch = true
+	} else {
+		cert.Issues = append(cert.Issues, "Logo embedded in the certificate differs from the published l= logo")
+	}
+}
+
+// findLogotypeDetails walks the DER tree of a logotype extension and returns
+// every LogotypeDetails (mediaType, hashes, URIs) it contains
+func findLogotypeDetails(der []byte, depth int) []logotypeDetails {
+	if depth > 16 {
+		return nil
+	}
+	var node asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &node); err != nil || !node.IsCompound {
+		return nil
+	}
+	var children []asn1.RawValue
+	for rest := node.Bytes; len(rest) > 0; {
+		var child asn1.RawValue
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &child); err != nil {
+			return nil
+		}
+		children = append(children, child)
+	}
+
+	if len(children) >= 3 && children[0].Class == asn1.ClassUniversal && children[0].Tag == asn1.TagIA5String &&
+		children[1].Tag == asn1.TagSequence && children[2].Tag == asn1.TagSequence {
+		d := logotypeDetails{mediaType: string(children[0].Bytes)}
+		var hashes []logotypeHash
+		if _, err := asn1.Unmarshal(children[1].FullBytes, &hashes); err == nil {
+			d.hashes = hashes
+		}
+		var uris []string
+		if _, err := asn1.Unmarshal(children[2].FullBytes, &uris); err == nil {
+			d.uris = uris
+		}
+		if len(d.hashes) > 0 && len(d.uris) > 0 {
+			return []logotypeDetails{d}
+		}
+	}
+
+	var found []logotypeDetails
+	for _, child := range children {
+		found = append(found, findLogotypeDetails(child.FullBytes, depth+1)...)
+	}
+	return found
+}
+
+// decodeDataURI returns the payload of a data: URI
+func decodeDataURI(uri string) ([]byte, error) {
+	header, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok {
+		return nil, fmt.Errorf("missing ','")
+	}
+	if strings.HasSuffix(strings.ToLower(header), ";base64") {
+		return base64.StdEncoding.DecodeString(payload)
+	}
+	decoded, err := url.PathUnescape(payload)
+	return []byte(decoded), err
+}
+
+// handleBIMI handles BIMI record, logo and certificate validation
+func handleBIMI(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/bimi", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	bimiInfo := checker.CheckBIMI(domain)
+	if ttl, ok := routeTTL["/api/v1/bimi"]; ok {
+		apiCache.Set(key, bimiInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    bimiInfo,
+	})
+}
+
+// -----------------------------
+// MTA-STS and TLS-RPT (RFC 8461, RFC 8460)
+// -----------------------------
+
+// mtaSTSIDRegex matches the id= value of an _mta-sts record
+var mtaSTSIDRegex = regexp.MustCompile(`^[A-Za-z0-9]{1,32}$`)
+
+// MTASTSMXResult represents how one MX host fares against the policy
+type MTASTSMXResult struct {
+	Host       string    `json:"host"`
+	Pattern    string    `json:"pattern,omitempty"` // mx pattern it matched
+	Matched    bool      `json:"matched"`
+	STARTTLS   bool      `json:"starttls"`
+	TLSVersion string    `json:"tls_version,omitempty"`
+	CertValid  bool      `json:"cert_valid"`
+	Subject    string    `json:"subject,omitempty"`
+	Issuer     string    `json:"issuer,omitempty"`
+	NotAfter   time.Time `json:"not_after,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// MTASTSInfo represents the MTA-STS record, policy and MX compliance
+type MTASTSInfo struct {
+	Configured bool             `json:"configured"`
+	Record     string           `json:"record,omitempty"`
+	ID         string           `json:"id,omitempty"`
+	PolicyURL  string           `json:"policy_url,omitempty"`
+	Policy     string           `json:"policy,omitempty"`
+	Mode       string           `json:"mode,omitempty"` // enforce, testing, none
+	MaxAge     int              `json:"max_age,omitempty"`
+	MXPatterns []string         `json:"mx_patterns,omitempty"`
+	MXHosts    []MTASTSMXResult `json:"mx_hosts,omitempty"`
+	MXProbed   bool             `json:"mx_probed"`
+	Valid      bool             `json:"valid"`
+	Errors     []string         `json:"errors,omitempty"`
+	Warnings   []string         `json:"warnings,omitempty"`
+	Details    string           `json:"details,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// TLSRPTInfo represents the SMTP TLS reporting record
+type TLSRPTInfo struct {
+	Configured bool     `json:"configured"`
+	Record     string   `json:"record,omitempty"`
+	RUA        []string `json:"rua,omitempty"`
+	Valid      bool     `json:"valid"`
+	Errors     []string `json:"errors,omitempty"`
+	Details    string   `json:"details,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// lookupVersionedTXT returns the TXT records at name that start with prefix
+func lookupVersionedTXT(name, prefix string) ([]string, error) {
+	txtRecords, err := net.LookupTXT(name)
+	if err != nil {
+		if isVoidLookup(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []string
+	for _, txt := range txtRecords {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(txt)), strings.ToLower(prefix)) {
+			records = append(records, txt)
+		}
+	}
+	return records, nil
+}
+
+// mtaSTSMatch reports whether host matches an MTA-STS mx pattern; a leading
+// "*." matches exactly one label (RFC 8461 section 4.1)
+func mtaSTSMatch(pattern, host string) bool {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if strings.HasPrefix(pattern, "*.") {
+		label, rest, ok := strings.Cut(host, ".")
+		return ok && label != "" && rest == pattern[2:]
+	}
+	return pattern == host
+}
+
+// CheckMTASTS checks the _mta-sts record, fetches the policy and confirms every
+// MX host matches it; certificates are checked by applyMTASTSProbes
+func (nc *NetChecker) CheckMTASTS(domain string) MTASTSInfo {
+	info := MTASTSInfo{}
+
+	records, err := lookupVersionedTXT("_mta-sts."+domain, "v=STSv1")
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to lookup MTA-STS record: %v", err)
+		info.Details = "No MTA-STS record found"
+		return info
+	}
+	if len(records) == 0 {
+		info.Details = "No MTA-STS record found"
+		return info
+	}
+
+	info.Configured = true
+	info.Record = records[0]
+	if len(records) > 1 {
+		info.Errors = append(info.Errors, fmt.Sprintf("%d MTA-STS records published; senders treat the domain as having none", len(records)))
+	}
+	tags, order, issues := parseTagList(records[0])
+	info.Errors = append(info.Errors, issues...)
+	if len(order) == 0 || order[0] != "v" || tags["v"] != "STSv1" {
+		info.Errors = append(info.Errors, "v=STSv1 must be the first tag")
+	}
+	info.ID = tags["id"]
+	if !mtaSTSIDRegex.MatchString(info.ID) {
+		info.Errors = append(info.Errors, "id= must be 1-32 alphanumeric characters")
+	}
+
+	// The policy must be served over HTTPS with a valid certificate and without redirects
+	res, err := nc.fetchWellKnown("mta-sts."+domain, "/.well-known/mta-sts.txt", true)
+	info.PolicyURL = res.url
+	switch {
+	case err != nil:
+		info.Errors = append(info.Errors, fmt.Sprintf("Failed to fetch policy: %v", err))
+	case res.finalURL != res.url:
+		info.Errors = append(info.Errors, fmt.Sprintf("Policy fetch was redirected to %s; redirects must not be followed", res.finalURL))
+	case res.status != http.StatusOK:
+		info.Errors = append(info.Errors, fmt.Sprintf("Policy fetch returned HTTP %d", res.status))
+	default:
+		info.Policy = string(res.body)
+		if mediaType(res.contentType) != "text/plain" {
+			info.Errors = append(info.Errors, fmt.Sprintf("Policy Content-Type must be text/plain, got %q", res.contentType))
+		}
+		policy, err := parseMTASTSPolicy(info.Policy)
+		if err != nil {
+			info.Errors = append(info.Errors, fmt.Sprintf("Invalid policy: %v", err))
+		}
+		info.Mode, _ = policy["mode"].(string)
+		info.MaxAge, _ = strconv.Atoi(fmt.Sprint(policy["max_age"]))
+		info.MXPatterns, _ = policy["mx"].([]string)
+	}
+
+	switch info.Mode {
+	case "testing":
+		info.Warnings = append(info.Warnings, "mode: testing only reports failures; set enforce to protect delivery")
+	case "none":
+		info.Warnings = append(info.Warnings, "mode: none withdraws the policy")
+	}
+	if info.Mode != "" && info.Mode != "none" && info.MaxAge < 86400 {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("max_age %d is under a day; weeks are recommended", info.MaxAge))
+	}
+
+	if info.Mode == "enforce" || info.Mode == "testing" {
+		nc.matchMTASTSHosts(&info, domain)
+	}
+
+	summarizeMTASTS(&info)
+	return info
+}
+
+// matchMTASTSHosts matches each MX host from CheckDNS against the policy
+func (nc *NetChecker) matchMTASTSHosts(info *MTASTSInfo, domain string) {
+	dnsInfo := nc.CheckDNS(domain)
+	info.MXHosts = make([]MTASTSMXResult, len(dnsInfo.MX))
+	for i, mx := range dnsInfo.MX {
+		// CheckDNS formats MX records as "host (priority: N)"
+		result := &info.MXHosts[i]
+		result.Host = strings.TrimSuffix(strings.Fields(mx)[0], ".")
+		for _, pattern := range info.MXPatterns {
+			if mtaSTSMatch(pattern, result.Host) {
+				result.Matched = true
+				result.Pattern = pattern
+				break
+			}
+		}
+		if !result.Matched {
+			info.addHostProblem(fmt.Sprintf("MX %s does not match any policy mx pattern", result.Host))
+		}
+	}
+}
+
+// addHostProblem records an MX problem; failures only block delivery in enforce mode
+func (info *MTASTSInfo) addHostProblem(problem string) {
+	if info.Mode == "enforce" {
+		info.Errors = append(info.Errors, problem)
+	} else {
+		info.Warnings = append(info.Warnings, problem)
+	}
+}
+
+// summarizeMTASTS sets the validity and summary from the collected errors
+func summarizeMTASTS(info *MTASTSInfo) {
+	info.Valid = len(info.Errors) == 0
+	if info.Valid {
+		info.Details = fmt.Sprintf("MTA-STS policy in %s mode", info.Mode)
+	} else {
+		info.Details = fmt.Sprintf("MTA-STS invalid: %s", info.Errors[0])
+	}
+}
+
+// skipMTASTSProbes notes that the MX certificates of an active policy were not
+// verified, so a valid result only covers the record, policy and mx patterns
+func skipMTASTSProbes(info *MTASTSInfo, reason string) {
+	if (info.Mode == "enforce" || info.Mode == "testing") && len(info.MXHosts) > 0 {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("MX certificates were not checked; %s", reason))
+	}
+}
+
+// applyMTASTSProbes verifies the STARTTLS certificate of every matched MX host
+// using port 25 results from CheckSMTP, so callers that already probed the MX
+// hosts do not connect twice
+func applyMTASTSProbes(info *MTASTSInfo, smtp SMTPInfo) {
+	if info.Mode != "enforce" && info.Mode != "testing" {
+		return
+	}
+	probes := make(map[string]SMTPHostResult, len(smtp.MXHosts))
+	for _, host := range smtp.MXHosts {
+		probes[host.Host] = host
+	}
+
+	info.MXProbed = true
+	for i := range info.MXHosts {
+		result := &info.MXHosts[i]
+		host, ok := probes[result.Host]
+		var probe *SMTPPortResult
+		for j := range host.Ports {
+			if host.Ports[j].Port == 25 {
+				probe = &host.Ports[j]
+			}
+		}
+		switch {
+		case !ok:
+			result.Error = "not probed"
+		case host.Error != "":
+			result.Error = host.Error
+		case probe == nil:
+			result.Error = "port 25 not probed"
+		default:
+			result.STARTTLS = probe.TLSVersion != ""
+			result.TLSVersion = probe.TLSVersion
+			if probe.Certificate != nil {
+				result.CertValid = probe.Certificate.Valid
+				result.Subject = probe.Certificate.Subject
+				result.Issuer = probe.Certificate.Issuer
+				result.NotAfter = probe.Certificate.NotAfter
+				result.Error = probe.Certificate.Error
+			}
+			switch {
+			case probe.Error != "":
+				result.Error = probe.Error
+			case !probe.STARTTLS:
+				result.Error = "server does not offer STARTTLS"
+			}
+		}
+		if result.Matched && !result.CertValid {
+			info.addHostProblem(fmt.Sprintf("MX %s does not present a valid certificate over STARTTLS: %s", result.Host, result.Error))
+		}
+	}
+	summarizeMTASTS(info)
+}
+
+// CheckTLSRPT checks the _smtp._tls TLS reporting record
+func (nc *NetChecker) CheckTLSRPT(domain string) TLSRPTInfo {
+	info := TLSRPTInfo{}
+
+	records, err := lookupVersionedTXT("_smtp._tls."+domain, "v=TLSRPTv1")
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to lookup TLS-RPT record: %v", err)
+		info.Details = "No TLS-RPT record found"
+		return info
+	}
+	if len(records) == 0 {
+		info.Details = "No TLS-RPT record found"
+		return info
+	}
+
+	info.Configured = true
+	info.Record = records[0]
+	if len(records) > 1 {
+		info.Errors = append(info.Errors, fmt.Sprintf("%d TLS-RPT records published; senders ignore all of them", len(records)))
+	}
+	tags, order, issues := parseTagList(records[0])
+	info.Errors = append(info.Errors, issues...)
+	if len(order) == 0 || order[0] != "v" || tags["v"] != "TLSRPTv1" {
+		info.Errors = append(info.Errors, "v=TLSRPTv1 must be the first tag")
+	}
+	if tags["rua"] == "" {
+		info.Errors = append(info.Errors, "Required rua= tag is missing")
+	}
+	for _, uri := range strings.Split(tags["rua"], ",") {
+		uri = strings.TrimSpace(uri)
+		if uri == "" {
+			continue
+		}
+		info.RUA = append(info.RUA, uri)
+		u, err := url.Parse(uri)
+		if err != nil || (u.Scheme != "mailto" && u.Scheme != "https") {
+			info.Errors = append(info.Errors, fmt.Sprintf("rua %s must be a mailto: or https: URI", uri))
+		}
+	}
+
+	info.Valid = len(info.Errors) == 0
+	if info.Valid {
+		info.Details = fmt.Sprintf("TLS-RPT reports sent to %s", strings.Join(info.RUA, ", "))
+	} else {
+		info.Details = fmt.Sprintf("TLS-RPT record invalid: %s", info.Errors[0])
+	}
+	return info
+}
+
+// -----------------------------
+// SMTP server probing
+// -----------------------------
+
+// smtpProbePorts are the ports /api/v1/smtp may probe: relay, implicit TLS
+// submission and STARTTLS submission
+var smtpProbePorts = []int{25, 465, 587}
+
+// SMTPPortResult represents one SMTP session to an MX host; the session ends
+// after EHLO (and STARTTLS) without sending mail
+type SMTPPortResult struct {
+	Port           int      `json:"port"`
+	ImplicitTLS    bool     `json:"implicit_tls"`
+	Connected      bool     `json:"connected"`
+	Banner         string   `json:"banner,omitempty"`
+	Capabilities   []string `json:"capabilities,omitempty"`
+	STARTTLS       bool     `json:"starttls"`
+	TLSVersion     string   `json:"tls_version,omitempty"`
+	CipherSuite    string   `json:"cipher_suite,omitempty"`
+	Certificate    *SSLInfo `json:"certificate,omitempty"`
+	AuthMechanisms []string `json:"auth_mechanisms,omitempty"`
+	SizeLimit      int64    `json:"size_limit,omitempty"`
+	ConnectMs      float64  `json:"connect_ms"`
+	BannerMs       float64  `json:"banner_ms"`
+	TLSHandshakeMs float64  `json:"tls_handshake_ms,omitempty"`
+	Error          string   `json:"error,omitempty"`
+
+	dialTimedOut bool // no answer to the connection attempt at all
+}
+
+// SMTPHostResult represents the probes of one MX host
+type SMTPHostResult struct {
+	Host     string           `json:"host"`
+	Priority int              `json:"priority"`
+	IP       string           `json:"ip,omitempty"`
+	Ports    []SMTPPortResult `json:"ports"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// SMTPInfo represents the SMTP probe results of a domain's MX hosts
+type SMTPInfo struct {
+	Domain  string           `json:"domain"`
+	MXHosts []SMTPHostResult `json:"mx_hosts"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// smtpHeloName is the name announced in EHLO, overridable with SMTP_HELO_NAME
+func smtpHeloName() string {
+	return getenvDefault("SMTP_HELO_NAME", "netcheck.local")
+}
+
+// smtpEHLO sends EHLO and records the capabilities it advertises
+func smtpEHLO(text *textproto.Conn, result *SMTPPortResult) error {
+	id, err := text.Cmd("EHLO %s", smtpHeloName())
+	if err != nil {
+		return err
+	}
+	text.StartResponse(id)
+	defer text.EndResponse(id)
+	_, msg, err := text.ReadResponse(250)
+	if err != nil {
+		return err
+	}
+
+	// The first line greets; the rest are extensions
+	lines := strings.Split(msg, "\n")
+	result.Capabilities = lines[1:]
+	result.STARTTLS = false
+	for _, line := range result.Capabilities {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "STARTTLS":
+			result.STARTTLS = true
+		case "AUTH":
+			result.AuthMechanisms = fields[1:]
+		case "SIZE":
+			if len(fields) > 1 {
+				result.SizeLimit, _ = strconv.ParseInt(fields[1], 10, 64)
+			}
+		}
+	}
+	return nil
+}
+
+// smtpTLS performs a TLS handshake over conn and records the negotiated
+// parameters; the certificate is verified separately so it is reported even
+// when invalid
+func smtpTLS(conn net.Conn, host string, result *SMTPPortResult) (*tls.Conn, error) {
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	start := time.Now()
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+	result.TLSHandshakeMs = float64(time.Since(start).Microseconds()) / 1000
+
+	state := tlsConn.ConnectionState()
+	result.TLSVersion = tls.VersionName(state.Version)
+	result.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	if len(state.PeerCertificates) > 0 {
+		leaf := state.PeerCertificates[0]
+		cert := certificateInfo(host, leaf)
+		intermediates := x509.NewCertPool()
+		for _, c := range state.PeerCertificates[1:] {
+			intermediates.AddCert(c)
+		}
+		if _, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Intermediates: intermediates}); err != nil {
+			cert.Valid = false
+			cert.Error = err.Error()
+		}
+		result.Certificate = &cert
+	}
+	return tlsConn, nil
+}
+
+// probeSMTP connects to host:port, reads the banner, sends EHLO and upgrades
+// with STARTTLS when offered (implicit TLS on 465), then quits
+func probeSMTP(host string, ip net.IP, port int) SMTPPortResult {
+	return probeSMTPAddr(host, net.JoinHostPort(ip.String(), strconv.Itoa(port)), port)
+}
+
+// probeSMTPAddr runs the probe against addr; port only selects the TLS mode
+// and is reported as given
+func probeSMTPAddr(host, addr string, port int) SMTPPortResult {
+	result := SMTPPortResult{Port: port, ImplicitTLS: port == 465}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		var netErr net.Error
+		result.dialTimedOut = errors.As(err, &netErr) && netErr.Timeout()
+		result.Error = err.Error()
+		return result
+	}
+	defer conn.Close()
+	result.ConnectMs = float64(time.Since(start).Microseconds()) / 1000
+	result.Connected = true
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	if result.ImplicitTLS {
+		tlsConn, err := smtpTLS(conn, host, &result)
+		if err != nil {
+			result.Error = fmt.Sprintf("TLS handshake failed: %v", err)
+			return result
+		}
+		conn = tlsConn
+	}
+
+	bannerStart := time.Now()
+	text := textproto.NewConn(conn)
+	_, banner, err := text.ReadResponse(220)
+	if err != nil {
+		result.Error = fmt.Sprintf("Unexpected banner: %v", err)
+		return result
+	}
+	result.BannerMs = float64(time.Since(bannerStart).Microseconds()) / 1000
+	result.Banner = banner
+
+	if err := smtpEHLO(text, &result); err != nil {
+		result.Error = fmt.Sprintf("EHLO failed: %v", err)
+		return result
+	}
+
+	if result.STARTTLS && !result.ImplicitTLS {
+		id, err := text.Cmd("STARTTLS")
+		if err == nil {
+			text.StartResponse(id)
+			_, _, err = text.ReadResponse(220)
+			text.EndResponse(id)
+		}
+		if err != nil {
+			result.Error = fmt.Sprintf("STARTTLS refused: %v", err)
+			return result
+		}
+		tlsConn, err := smtpTLS(conn, host, &result)
+		if err != nil {
+			result.Error = fmt.Sprintf("STARTTLS handshake failed: %v", err)
+			return result
+		}
+		conn = tlsConn
+		text = textproto.NewConn(conn)
+
+		// Capabilities such as AUTH are often only offered after STARTTLS
+		if err := smtpEHLO(text, &result); err != nil {
+			result.Error = fmt.Sprintf("EHLO after STARTTLS failed: %v", err)
+			return result
+		}
+		result.STARTTLS = true
+	}
+
+	text.Cmd("QUIT")
+	text.ReadResponse(221)
+	return result
+}
+
+// CheckSMTP probes every MX host of domain on the given ports
+func (nc *NetChecker) CheckSMTP(domain string, ports []int) SMTPInfo {
+	info := SMTPInfo{Domain: domain}
+
+	dnsInfo := nc.CheckDNS(domain)
+	if len(dnsInfo.MX) == 0 {
+		info.Error = "No MX records found"
+		return info
+	}
+
+	info.MXHosts = make([]SMTPHostResult, len(dnsInfo.MX))
+	var wg sync.WaitGroup
+	for i, mx := range dnsInfo.MX {
+		host := &info.MXHosts[i]
+		// CheckDNS formats MX records as "host (priority: N)"
+		if _, err := fmt.Sscanf(mx, "%s (priority: %d)", &host.Host, &host.Priority); err != nil {
+			host.Host = strings.Fields(mx)[0]
+		}
+		host.Host = strings.TrimSuffix(host.Host, ".")
+
+		ip, err := resolveTarget(host.Host)
+		if err != nil {
+			host.Error = err.Error()
+			continue
+		}
+		host.IP = ip.String()
+		host.Ports = make([]SMTPPortResult, len(ports))
+		for j, port := range ports {
+			wg.Add(1)
+			go func(host *SMTPHostResult, ip net.IP, j, port int) {
+				defer wg.Done()
+				host.Ports[j] = probeSMTP(host.Host, ip, port)
+			}(host, ip, j, port)
+		}
+	}
+	wg.Wait()
+
+	sort.SliceStable(info.MXHosts, func(i, j int) bool { return info.MXHosts[i].Priority < info.MXHosts[j].Priority })
+	return info
+}
+
+// handleSMTP handles SMTP probing of a domain's MX hosts
+func handleSMTP(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	ports := []int{25}
+	if spec := c.Query("ports"); spec != "" {
+		ports = nil
+		for _, p := range strings.Split(spec, ",") {
+			port, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil || !slices.Contains(smtpProbePorts, port) {
+				c.JSON(http.StatusBadRequest, APIResponse{
+					Success: false,
+					Error:   "ports may only contain 25, 465 and 587",
+				})
+				return
+			}
+			if !slices.Contains(ports, port) {
+				ports = append(ports, port)
+			}
+		}
+	}
+
+	portKey := make([]string, len(ports))
+	for i, p := range ports {
+		portKey[i] = strconv.Itoa(p)
+	}
+	key := cacheKey("/api/v1/smtp", map[string]string{"domain": domain, "ports": strings.Join(portKey, ",")})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	smtpInfo := checker.CheckSMTP(domain, ports)
+	if ttl, ok := routeTTL["/api/v1/smtp"]; ok {
+		apiCache.Set(key, smtpInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    smtpInfo,
+	})
+}
+
+// -----------------------------
+// Email header analysis
+// -----------------------------
+
+const (
+	maxEmailMessageBytes = 2 << 20
+	maxDKIMSignatures    = 10 // DKIM-Signature headers verified per message
+)
+
+var (
+	receivedClauseRegex = regexp.MustCompile(`(?i)\b(from|by|via|with|id|for)\s+([^\s;]+)`)
+	receivedIPRegex     = regexp.MustCompile(`\[(?:IPv6:)?([0-9A-Fa-f:.]+)\]`)
+	dkimBTagRegex       = regexp.MustCompile(`([:;]\s*b\s*=)[^;]*`)
+	headerWSPRegex      = regexp.MustCompile(`[ \t]+`)
+)
+
+// EmailHeaderRequest is the JSON form of an email header analysis request
+type EmailHeaderRequest struct {
+	Headers string `json:"headers"`
+}
+
+// ReceivedHop represents one Received header, oldest hop first
+type ReceivedHop struct {
+	Index        int        `json:"index"`
+	From         string     `json:"from,omitempty"`
+	By           string     `json:"by,omitempty"`
+	With         string     `json:"with,omitempty"`
+	ID           string     `json:"id,omitempty"`
+	For          string     `json:"for,omitempty"`
+	IP           string     `json:"ip,omitempty"`
+	Date         *time.Time `json:"date,omitempty"`
+	DelaySeconds float64    `json:"delay_seconds"`
+	Raw          string     `json:"raw"`
+}
+
+// AuthMethodResult is one method result of an Authentication-Results header
+type AuthMethodResult struct {
+	Method     string            `json:"method"`
+	Result     string            `json:"result"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// AuthResultsHeader represents one Authentication-Results header (RFC 8601)
+type AuthResultsHeader struct {
+	AuthServID string             `json:"authserv_id"`
+	Results    []AuthMethodResult `json:"results"`
+}
+
+// ARCSet groups the ARC headers of one instance (RFC 8617)
+type ARCSet struct {
+	Instance              int               `json:"instance"`
+	CV                    string            `json:"cv,omitempty"`
+	Domain                string            `json:"domain,omitempty"`
+	Selector              string            `json:"selector,omitempty"`
+	Seal                  map[string]string `json:"seal,omitempty"`
+	MessageSignature      map[string]string `json:"message_signature,omitempty"`
+	AuthenticationResults string            `json:"authentication_results,omitempty"`
+}
+
+// DKIMSignatureResult represents the verification of one DKIM-Signature
+type DKIMSignatureResult struct {
+	Domain           string            `json:"domain"`
+	Selector         string            `json:"selector"`
+	Algorithm        string            `json:"algorithm"`
+	Canonicalization string            `json:"canonicalization"`
+	SignedHeaders    []string          `json:"signed_headers"`
+	Identity         string            `json:"identity,omitempty"`
+	Timestamp        *time.Time        `json:"timestamp,omitempty"`
+	Expiration       *time.Time        `json:"expiration,omitempty"`
+	Tags             map[string]string `json:"tags"`
+	Key              *DKIMKeyInfo      `json:"key,omitempty"`
+	SignatureValid   bool              `json:"signature_valid"`
+	BodyVerified     bool              `json:"body_verified"`
+	BodyHashMatch    bool              `json:"body_hash_match"`
+	Aligned          bool              `json:"aligned"`
+	Result           string            `json:"result"` // pass, fail, permerror, temperror
+	Error            string            `json:"error,omitempty"`
+}
+
+// EmailHeaderAnalysis represents the analysis of a message's headers
+type EmailHeaderAnalysis struct {
+	From                  string                `json:"from,omitempty"`
+	FromDomain            string                `json:"from_domain,omitempty"`
+	ReturnPath            string                `json:"return_path,omitempty"`
+	Subject               string                `json:"subject,omitempty"`
+	MessageID             string                `json:"message_id,omitempty"`
+	Date                  string                `json:"date,omitempty"`
+	BodyIncluded          bool                  `json:"body_included"`
+	Hops                  []ReceivedHop         `json:"hops"`
+	TotalDelaySeconds     float64               `json:"total_delay_seconds"`
+	AuthenticationResults []AuthResultsHeader   `json:"authentication_results,omitempty"`
+	ARC                   []ARCSet              `json:"arc,omitempty"`
+	ARCResult             string                `json:"arc_result"` // none, pass, fail
+	DKIM                  []DKIMSignatureResult `json:"dkim,omitempty"`
+	OriginatingIP         string                `json:"originating_ip,omitempty"`
+	SPF                   *SPFCheckResult       `json:"spf,omitempty"`
+	SPFAligned            bool                  `json:"spf_aligned"`
+	DKIMAligned           bool                  `json:"dkim_aligned"`
+	DMARC                 *DMARCInfo            `json:"dmarc,omitempty"`
+	DMARCResult           string                `json:"dmarc_result"` // pass, fail, none
+	Issues                []string              `json:"issues,omitempty"`
+}
+
+// rawHeaderField keeps a header field exactly as received, folding included,
+// as DKIM simple canonicalization needs the original bytes
+type rawHeaderField struct {
+	name string
+	raw  string
+}
+
+// value returns the unfolded, trimmed field value
+func (f rawHeaderField) value() string {
+	_, v, _ := strings.Cut(f.raw, ":")
+	return strings.TrimSpace(strings.NewReplacer("\r\n", "", "\n", "").Replace(v))
+}
+
+// splitRawMessage splits a message into header fields and body, normalizing
+// line endings to CRLF
+func splitRawMessage(message string) ([]rawHeaderField, string, bool) {
+	message = strings.ReplaceAll(strings.ReplaceAll(message, "\r\n", "\n"), "\n", "\r\n")
+	head, body, hasBody := strings.Cut(message, "\r\n\r\n")
+
+	var fields []rawHeaderField
+	for i, line := range strings.Split(head, "\r\n") {
+		// Skip an mbox "From " separator
+		if i == 0 && strings.HasPrefix(line, "From ") {
+			continue
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(fields) > 0 {
+			fields[len(fields)-1].raw += "\r\n" + line
+			continue
+		}
+		name, _, ok := strings.Cut(line, ":")
+		if !ok || strings.ContainsAny(name, " \t") {
+			continue
+		}
+		fields = append(fields, rawHeaderField{name: name, raw: line})
+	}
+	return fields, body, hasBody && strings.TrimSpace(body) != ""
+}
+
+// headerValues returns the values of every field named name, in message order
+func headerValues(fields []rawHeaderField, name string) []string {
+	var values []string
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			values = append(values, f.value())
+		}
+	}
+	return values
+}
+
+// stripHeaderComments removes (possibly nested) RFC 5322 comments
+func stripHeaderComments(value string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range value {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// parseReceived parses the clauses and date of a Received header
+func parseReceived(value string) ReceivedHop {
+	hop := ReceivedHop{Raw: value}
+	clauses := value
+	if i := strings.LastIndex(value, ";"); i >= 0 {
+		clauses = value[:i]
+		if date, err := mail.ParseDate(strings.TrimSpace(value[i+1:])); err == nil {
+			hop.Date = &date
+		}
+	}
+
+	for _, m := range receivedClauseRegex.FindAllStringSubmatch(stripHeaderComments(clauses), -1) {
+		target := map[string]*string{"from": &hop.From, "by": &hop.By, "with": &hop.With, "id": &hop.ID, "for": &hop.For}[strings.ToLower(m[1])]
+		if target != nil && *target == "" {
+			*target = strings.Trim(m[2], "<>")
+		}
+	}
+
+	// The connecting IP is in the from clause comment, e.g. "(mail.example.com [192.0.2.1])"
+	fromPart := clauses
+	if i := strings.Index(strings.ToLower(clauses), " by "); i >= 0 {
+		fromPart = clauses[:i]
+	}
+	if m := receivedIPRegex.FindStringSubmatch(fromPart); m != nil && net.ParseIP(m[1]) != nil {
+		hop.IP = m[1]
+	}
+	return hop
+}
+
+// parseAuthenticationResults parses an Authentication-Results header value
+func parseAuthenticationResults(value string) AuthResultsHeader {
+	parts := strings.Split(stripHeaderComments(value), ";")
+	var header AuthResultsHeader
+	if id := strings.Fields(parts[0]); len(id) > 0 {
+		header.AuthServID = id[0]
+	}
+	for _, part := range parts[1:] {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		method, result, ok := strings.Cut(fields[0], "=")
+		if !ok {
+			continue
+		}
+		res := AuthMethodResult{Method: strings.ToLower(method), Result: strings.ToLower(result)}
+		for _, prop := range fields[1:] {
+			if k, v, ok := strings.Cut(prop, "="); ok {
+				if res.Properties == nil {
+					res.Properties = make(map[string]string)
+				}
+				res.Properties[strings.ToLower(k)] = strings.Trim(v, `"`)
+			}
+		}
+		header.Results = append(header.Results, res)
+	}
+	return header
+}
+
+// parseARCSets groups ARC headers by instance and evaluates the declared chain
+func parseARCSets(fields []rawHeaderField) ([]ARCSet, string) {
+	sets := make(map[int]*ARCSet)
+	get := func(i int) *ARCSet {
+		if sets[i] == nil {
+			sets[i] = &ARCSet{Instance: i}
+		}
+		return sets[i]
+	}
+	for _, f := range fields {
+		value := f.value()
+		switch strings.ToLower(f.name) {
+		case "arc-seal":
+			tags, _, _ := parseTagList(value)
+			i, _ := strconv.Atoi(tags["i"])
+			set := get(i)
+			set.Seal = tags
+			set.CV = strings.ToLower(tags["cv"])
+		case "arc-message-signature":
+			tags, _, _ := parseTagList(value)
+			i, _ := strconv.Atoi(tags["i"])
+			set := get(i)
+			set.MessageSignature = tags
+			set.Domain, set.Selector = tags["d"], tags["s"]
+		case "arc-authentication-results":
+			instance, rest, _ := strings.Cut(value, ";")
+			i, _ := strconv.Atoi(strings.TrimPrefix(strings.ReplaceAll(instance, " ", ""), "i="))
+			get(i).AuthenticationResults = strings.TrimSpace(rest)
+		}
+	}
+	if len(sets) == 0 {
+		return nil, "none"
+	}
+
+	var out []ARCSet
+	result := "pass"
+	for i := 1; i <= len(sets); i++ {
+		set, ok := sets[i]
+		if !ok || set.Seal == nil || set.MessageSignature == nil || set.AuthenticationResults == "" {
+			return append(out, ARCSet{Instance: i}), "fail"
+		}
+		if (i == 1 && set.CV != "none") || (i > 1 && set.CV != "pass") {
+			result = "fail"
+		}
+		out = append(out, *set)
+	}
+	return out, result
+}
+
+// dkimCanonicalizeHeader applies simple or relaxed header canonicalization
+func dkimCanonicalizeHeader(raw string, relaxed bool) string {
+	if !relaxed {
+		return raw + "\r\n"
+	}
+	name, value, _ := strings.Cut(raw, ":")
+	value = headerWSPRegex.ReplaceAllString(strings.ReplaceAll(value, "\r\n", ""), " ")
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + strings.TrimSpace(value) + "\r\n"
+}
+
+// dkimCanonicalizeBody applies simple or relaxed body canonicalization
+func dkimCanonicalizeBody(body string, relaxed bool) string {
+	if relaxed {
+		lines := strings.Split(body, "\r\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(headerWSPRegex.ReplaceAllString(line, " "), " ")
+		}
+		body = strings.Join(lines, "\r\n")
+	}
+	body = strings.TrimRight(body, "\r\n")
+	if body == "" {
+		if relaxed {
+			return ""
+		}
+		return "\r\n"
+	}
+	return body + "\r\n"
+}
+
+// verifyDKIMSignature verifies one DKIM-Signature against the key in DNS. The
+// header signature covers bh=, so it verifies without the body; the body hash
+// is only checked when the body was supplied.
+func verifyDKIMSignature(sigField rawHeaderField, fields []rawHeaderField, body string, hasBody bool) DKIMSignatureResult {
+	tags, _, issues := parseTagList(sigField.value())
+	res := DKIMSignatureResult{
+		Domain:           strings.ToLower(tags["d"]),
+		Selector:         tags["s"],
+		Algorithm:        strings.ToLower(tags["a"]),
+		Canonicalization: "simple/simple",
+		Identity:         tags["i"],
+		Tags:             tags,
+		Result:           "permerror",
+	}
+	for _, h := range strings.Split(tags["h"], ":") {
+		if h = strings.TrimSpace(h); h != "" {
+			res.SignedHeaders = append(res.SignedHeaders, h)
+		}
+	}
+	if c, ok := tags["c"]; ok {
+		res.Canonicalization = strings.ToLower(c)
+		if !strings.Contains(c, "/") {
+			res.Canonicalization += "/simple"
+		}
+	}
+	if t, err := strconv.ParseInt(tags["t"], 10, 64); err == nil {
+		ts := time.Unix(t, 0).UTC()
+		res.Timestamp = &ts
+	}
+	if x, err := strconv.ParseInt(tags["x"], 10, 64); err == nil {
+		exp := time.Unix(x, 0).UTC()
+		res.Expiration = &exp
+	}
+
+	switch {
+	case len(issues) > 0:
+		res.Error = issues[0]
+		return res
+	case tags["v"] != "1":
+		res.Error = "v= must be 1"
+		return res
+	case res.Domain == "" || res.Selector == "" || tags["b"] == "" || tags["bh"] == "" || len(res.SignedHeaders) == 0:
+		res.Error = "Required tag (d, s, b, bh or h) is missing"
+		return res
+	case !slices.ContainsFunc(res.SignedHeaders, func(h string) bool { return strings.EqualFold(h, "from") }):
+		res.Error = "h= does not include From"
+		return res
+	case res.Expiration != nil && res.Expiration.Before(time.Now()):
+		res.Result = "fail"
+		res.Error = "Signature has expired (x=)"
+		return res
+	}
+
+	var hashFn crypto.Hash
+	switch res.Algorithm {
+	case "rsa-sha256", "ed25519-sha256":
+		hashFn = crypto.SHA256
+	case "rsa-sha1":
+		hashFn = crypto.SHA1
+	default:
+		res.Error = fmt.Sprintf("Unsupported algorithm a=%s", res.Algorithm)
+		return res
+	}
+	headerCanon, bodyCanon, _ := strings.Cut(res.Canonicalization, "/")
+
+	// A missing key is a permanent failure; only a failed lookup is temporary
+	key, found, err := lookupDKIMKey(res.Domain, res.Selector)
+	if err != nil {
+		res.Result = "temperror"
+		res.Error = fmt.Sprintf("Key lookup for %s._domainkey.%s failed: %v", res.Selector, res.Domain, err)
+		return res
+	}
+	if !found {
+		res.Error = fmt.Sprintf("No key at %s._domainkey.%s", res.Selector, res.Domain)
+		return res
+	}
+	res.Key = &key
+	if key.publicKey == nil {
+		res.Error = "Key record is revoked or unusable"
+		return res
+	}
+
+	// Body hash, when the body is available
+	if hasBody {
+		canon := dkimCanonicalizeBody(body, bodyCanon == "relaxed")
+		if l, err := strconv.Atoi(tags["l"]); err == nil && l < len(canon) {
+			canon = canon[:l]
+		}
+		h := hashFn.New()
+		h.Write([]byte(canon))
+		res.BodyVerified = true
+		res.BodyHashMatch = base64.StdEncoding.EncodeToString(h.Sum(nil)) == tags["bh"]
+	}
+
+	// Header hash: signed headers, bottom-up per name, then the signature with b= emptied
+	h := hashFn.New()
+	used := make(map[int]bool)
+	for _, name := range res.SignedHeaders {
+		for i := len(fields) - 1; i >= 0; i-- {
+			if !used[i] && strings.EqualFold(fields[i].name, name) {
+				used[i] = true
+				h.Write([]byte(dkimCanonicalizeHeader(fields[i].raw, headerCanon == "relaxed")))
+				break
+			}
+		}
+	}
+	sigRaw := dkimBTagRegex.ReplaceAllString(sigField.raw, "$1")
+	h.Write([]byte(strings.TrimSuffix(dkimCanonicalizeHeader(sigRaw, headerCanon == "relaxed"), "\r\n")))
+	digest := h.Sum(nil)
+
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		res.Error = fmt.Sprintf("b= is not valid base64: %v", err)
+		return res
+	}
+	switch pub := key.publicKey.(type) {
+	case *rsa.PublicKey:
+		res.SignatureValid = rsa.VerifyPKCS1v15(pub, hashFn, digest, sig) == nil
+	case ed25519.PublicKey:
+		res.SignatureValid = ed25519.Verify(pub, digest, sig)
+	}
+
+	switch {
+	case !res.SignatureValid:
+		res.Result = "fail"
+		res.Error = "Header signature does not verify"
+	case res.BodyVerified && !res.BodyHashMatch:
+		res.Result = "fail"
+		res.Error = "Body hash does not match bh="
+	default:
+		res.Result = "pass"
+	}
+	return res
+}
+
+// dmarcAligned compares two domains in strict (s) or relaxed (r) mode
+func dmarcAligned(a, b, mode string) bool {
+	a, b = strings.ToLower(strings.TrimSuffix(a, ".")), strings.ToLower(strings.TrimSuffix(b, "."))
+	if a == "" || b == "" {
+		return false
+	}
+	if mode == "s" {
+		return a == b
+	}
+	orgA, errA := publicsuffix.EffectiveTLDPlusOne(a)
+	orgB, errB := publicsuffix.EffectiveTLDPlusOne(b)
+	return errA == nil && errB == nil && orgA == orgB
+}
+
+// addressDomain returns the domain of an address header value
+func addressDomain(value string) (string, string) {
+	addr := strings.Trim(strings.TrimSpace(value), "<>")
+	if parsed, err := mail.ParseAddress(value); err == nil {
+		addr = parsed.Address
+	}
+	if at := strings.LastIndex(addr, "@"); at >= 0 {
+		return addr, strings.ToLower(addr[at+1:])
+	}
+	return addr, ""
+}
+
+// spfReceivedHop returns the hop where the message entered the receiver's
+// infrastructure, the one whose from-IP the receiver checked SPF against. The
+// receiver is the organizational domain of the newest named by host; hops below
+// it are walked down past the receiver's own relays until a public address
+// outside it. Without such a hop the newest public from-IP is used.
+func spfReceivedHop(hops []ReceivedHop) *ReceivedHop {
+	public := func(hop ReceivedHop) bool {
+		ip := net.ParseIP(hop.IP)
+		return ip != nil && isRoutableIP(ip)
+	}
+
+	receiver := ""
+	for i := len(hops) - 1; i >= 0 && receiver == ""; i-- {
+		if by := strings.Trim(hops[i].By, "[]"); by != "" && net.ParseIP(by) == nil {
+			receiver = by
+		}
+	}
+	if receiver != "" {
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := hops[i]
+			if hop.By == "" || net.ParseIP(strings.Trim(hop.By, "[]")) != nil {
+				continue
+			}
+			if !dmarcAligned(hop.By, receiver, "r") {
+				break
+			}
+			if public(hop) && !dmarcAligned(hop.From, receiver, "r") {
+				return &hops[i]
+			}
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		if public(hops[i]) {
+			return &hops[i]
+		}
+	}
+	return nil
+}
+
+// AnalyzeEmailHeaders parses a message's headers and re-checks DKIM, SPF and
+// DMARC against live DNS
+func (nc *NetChecker) AnalyzeEmailHeaders(message string) EmailHeaderAnalysis {
+	var analysis EmailHeaderAnalysis
+	fields, body, hasBody := splitRawMessage(message)
+	analysis.BodyIncluded = hasBody
+
+	first := func(name string) string {
+		if values := headerValues(fields, name); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	analysis.From = first("From")
+	analysis.Subject = first("Subject")
+	analysis.MessageID = first("Message-ID")
+	analysis.Date = first("Date")
+	_, analysis.FromDomain = addressDomain(analysis.From)
+	if analysis.FromDomain == "" {
+		analysis.Issues = append(analysis.Issues, "No From address found")
+	}
+	if len(headerValues(fields, "From")) > 1 {
+		analysis.Issues = append(analysis.Issues, "Multiple From headers; DMARC receivers reject such messages")
+	}
+	returnPath := first("Return-Path")
+	var mailFromDomain string
+	analysis.ReturnPath, mailFromDomain = addressDomain(returnPath)
+
+	// Received headers are prepended, so the oldest hop is last
+	received := headerValues(fields, "Received")
+	for i := len(received) - 1; i >= 0; i-- {
+		hop := parseReceived(received[i])
+		hop.Index = len(analysis.Hops) + 1
+		if n := len(analysis.Hops); n > 0 && hop.Date != nil && analysis.Hops[n-1].Date != nil {
+			hop.DelaySeconds = hop.Date.Sub(*analysis.Hops[n-1].Date).Seconds()
+			if hop.DelaySeconds < 0 {
+				analysis.Issues = append(analysis.Issues, fmt.Sprintf("Hop %d is dated before the previous hop; clocks are skewed or the header was forged", hop.Index))
+			}
+		}
+		analysis.Hops = append(analysis.Hops, hop)
+	}
+	if n := len(analysis.Hops); n > 1 && analysis.Hops[0].Date != nil && analysis.Hops[n-1].Date != nil {
+		analysis.TotalDelaySeconds = analysis.Hops[n-1].Date.Sub(*analysis.Hops[0].Date).Seconds()
+	}
+
+	for _, value := range headerValues(fields, "Authentication-Results") {
+		analysis.AuthenticationResults = append(analysis.AuthenticationResults, parseAuthenticationResults(value))
+	}
+	analysis.ARC, analysis.ARCResult = parseARCSets(fields)
+
+	// DMARC policy drives the alignment modes
+	adkim, aspf := "r", "r"
+	if analysis.FromDomain != "" {
+		dmarc := nc.CheckDMARC(analysis.FromDomain)
+		analysis.DMARC = &dmarc
+		if dmarc.Configured {
+			adkim, aspf = dmarc.ADKIM, dmarc.ASPF
+		}
+	}
+
+	signatures := 0
+	for _, f := range fields {
+		if !strings.EqualFold(f.name, "DKIM-Signature") {
+			continue
+		}
+		if signatures++; signatures > maxDKIMSignatures {
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Only the first %d DKIM signatures were verified", maxDKIMSignatures))
+			break
+		}
+		res := verifyDKIMSignature(f, fields, body, hasBody)
+		res.Aligned = dmarcAligned(res.Domain, analysis.FromDomain, adkim)
+		if res.Result == "pass" && res.Aligned {
+			analysis.DKIMAligned = true
+		}
+		analysis.DKIM = append(analysis.DKIM, res)
+	}
+	if !hasBody && len(analysis.DKIM) > 0 {
+		analysis.Issues = append(analysis.Issues, "Only headers were supplied; DKIM body hashes were not verified")
+	}
+
+	// SPF is evaluated for the address the receiving infrastructure accepted the message from
+	if hop := spfReceivedHop(analysis.Hops); hop != nil {
+		analysis.OriginatingIP = hop.IP
+		helo := hop.From
+		spfDomain, mailFrom := mailFromDomain, analysis.ReturnPath
+		if spfDomain == "" {
+			// Null reverse-path: SPF checks the HELO identity (RFC 7208 section 2.4)
+			spfDomain, mailFrom = helo, ""
+		}
+		if spfDomain != "" {
+			spf := nc.CheckSPFHost(spfDomain, hop.IP, helo, mailFrom)
+			analysis.SPF = &spf
+			analysis.SPFAligned = spf.Result == "pass" && dmarcAligned(spfDomain, analysis.FromDomain, aspf)
+		}
+	}
+	if analysis.SPF == nil {
+		analysis.Issues = append(analysis.Issues, "No public originating IP in the Received chain; SPF was not evaluated")
+	}
+
+	switch {
+	case analysis.DMARC == nil || !analysis.DMARC.Configured:
+		analysis.DMARCResult = "none"
+	case analysis.DKIMAligned || analysis.SPFAligned:
+		analysis.DMARCResult = "pass"
+	default:
+		analysis.DMARCResult = "fail"
+	}
+	return analysis
+}
+
+// handleEmailHeaders analyzes raw message headers posted as text or JSON
+func handleEmailHeaders(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxEmailMessageBytes)
+
+	var message string
+	if strings.HasPrefix(c.ContentType(), "application/json") {
+		var req EmailHeaderRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid request body: %v", err),
+			})
+			return
+		}
+		message = req.Headers
+	} else {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to read request body: %v", err),
+			})
+			return
+		}
+		message = string(body)
+	}
+
+	if strings.TrimSpace(message) == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Email headers are required",
+		})
+		return
+	}
+
+	checker := NewNetChecker()
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    checker.AnalyzeEmailHeaders(message),
+	})
+}
+
+// -----------------------------
+// Email deliverability score
+// -----------------------------
+
+// deliverabilityWeights are the points each check contributes to the 100 point score
+var deliverabilityWeights = map[string]int{
+	"dmarc":   20,
+	"spf":     15,
+	"dkim":    15,
+	"mx":      15,
+	"dnsbl":   15,
+	"ptr":     10,
+	"mta_sts": 5,
+	"bimi":    5,
+}
+
+// deliverabilityOrder is the order checks are reported in
+var deliverabilityOrder = []string{"spf", "dkim", "dmarc", "mx", "ptr", "dnsbl", "mta_sts", "bimi"}
+
+// DeliverabilityCheck represents the outcome of one scored check
+type DeliverabilityCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // pass, warn, fail, unknown
+	Weight  int    `json:"weight"`
+	Points  int    `json:"points"`
+	Details string `json:"details"`
+}
+
+// DeliverabilityRecommendation is an action ordered by the points it recovers
+type DeliverabilityRecommendation struct {
+	Priority string `json:"priority"` // high, medium, low
+	Check    string `json:"check"`
+	Impact   int    `json:"impact"`
+	Action   string `json:"action"`
+}
+
+// MXDeliverability represents reachability, reverse DNS and reputation of an MX host
+type MXDeliverability struct {
+	Host      string   `json:"host"`
+	IP        string   `json:"ip,omitempty"`
+	Reachable bool     `json:"reachable"`
+	STARTTLS  bool     `json:"starttls"`
+	PTR       string   `json:"ptr,omitempty"`
+	FCrDNS    bool     `json:"fcrdns"`
+	Listings  []string `json:"listings,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// DeliverabilityInfo represents the aggregated deliverability report of a domain
+type DeliverabilityInfo struct {
+	Domain          string                         `json:"domain"`
+	Score           int                            `json:"score"`
+	Grade           string                         `json:"grade"`
+	Checks          []DeliverabilityCheck          `json:"checks"`
+	Recommendations []DeliverabilityRecommendation `json:"recommendations"`
+	MXHosts         []MXDeliverability             `json:"mx_hosts"`
+	EmailConfig     EmailConfigInfo                `json:"email_config"`
+}
+
+// checkFCrDNS returns the first PTR name of ip and whether it resolves back to ip
+func checkFCrDNS(ip string) (string, bool) {
+	names, err := net.LookupAddr(ip)
+	if err != nil || len(names) == 0 {
+		return "", false
+	}
+	for _, name := range names {
+		addrs, err := net.LookupHost(strings.TrimSuffix(name, "."))
+		if err == nil && contains(addrs, ip) {
+			return strings.TrimSuffix(name, "."), true
+		}
+	}
+	return strings.TrimSuffix(names[0], "."), false
+}
+
+// CheckDeliverability combines the email authentication checks with MX
+// reachability, reverse DNS and DNSBL listings into a weighted score
+func (nc *NetChecker) CheckDeliverability(domain string) DeliverabilityInfo {
+	cleanDomain := strings.TrimPrefix(domain, "https://")
+	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
+	cleanDomain = strings.Split(cleanDomain, "/")[0]
+	info := DeliverabilityInfo{Domain: cleanDomain}
+
+	var smtpInfo SMTPInfo
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		info.EmailConfig = nc.checkEmailConfig(cleanDomain)
+	}()
+	go func() {
+		defer wg.Done()
+		smtpInfo = nc.CheckSMTP(cleanDomain, []int{25})
+	}()
+	wg.Wait()
+
+	probed, timedOut := 0, 0
+	info.MXHosts = make([]MXDeliverability, len(smtpInfo.MXHosts))
+	for i, host := range smtpInfo.MXHosts {
+		mx := &info.MXHosts[i]
+		mx.Host, mx.IP, mx.Error = host.Host, host.IP, host.Error
+		if len(host.Ports) > 0 {
+			mx.Reachable = host.Ports[0].Banner != ""
+			mx.STARTTLS = host.Ports[0].TLSVersion != ""
+			if host.Ports[0].Error != "" {
+				mx.Error = host.Ports[0].Error
+			}
+			probed++
+			if host.Ports[0].dialTimedOut {
+				timedOut++
+			}
+		}
+		if mx.IP == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(mx *MXDeliverability) {
+			defer wg.Done()
+			mx.PTR, mx.FCrDNS = checkFCrDNS(mx.IP)
+			for _, r := range nc.CheckIPReputation(mx.IP).Results {
+				if r.Listed {
+					mx.Listings = append(mx.Listings, r.Name)
+				}
+			}
+		}(mx)
+	}
+	wg.Wait()
+
+	// When no MX host answers at all, outbound port 25 is most likely blocked here,
+	// so neither reachability nor the MTA-STS certificates can be judged
+	egressBlocked := probed > 0 && timedOut == probed
+	if egressBlocked {
+		skipMTASTSProbes(&info.EmailConfig.MTASTS, "no MX host answered on port 25")
+	} else {
+		applyMTASTSProbes(&info.EmailConfig.MTASTS, smtpInfo)
+	}
+
+	checks := make(map[string]*DeliverabilityCheck)
+	actions := make(map[string]string)
+	set := func(name, status, details, action string) {
+		checks[name] = &DeliverabilityCheck{Name: name, Status: status, Weight: deliverabilityWeights[name], Details: details}
+		actions[name] = action
+	}
+	email := info.EmailConfig
+
+	// SPF
+	spf := email.SPF
+	switch {
+	case !spf.Configured:
+		set("spf", "fail", "No SPF record", "Publish an SPF record that lists your sending services and ends in ~all or -all")
+	case !spf.Valid:
+		set("spf", "fail", spf.Details, fmt.Sprintf("Fix the SPF record: %s", strings.Join(spf.Errors, "; ")))
+	case spf.AllQualifier == "+" || spf.AllQualifier == "?" || spf.AllQualifier == "":
+		set("spf", "warn", fmt.Sprintf("SPF record does not reject other senders (all qualifier %q)", spf.AllQualifier), "End the SPF record with ~all or -all so other senders fail")
+	default:
+		set("spf", "pass", spf.Details, "")
+	}
+
+	// DKIM
+	dkim := email.DKIM
+	weakOnly := true
+	for _, key := range dkim.Keys {
+		if key.Valid && !key.Testing {
+			weakOnly = false
+		}
+	}
+	switch {
+	case !dkim.Configured && len(dkim.Failed) > 0:
+		set("dkim", "unknown", dkim.Details, "")
+	case !dkim.Configured:
+		set("dkim", "fail", "No DKIM key found for common selectors", "Sign outgoing mail with a 2048-bit DKIM key; if you already sign with a custom selector, confirm it with /api/v1/dkim?selectors=")
+	case !dkim.Valid || weakOnly:
+		set("dkim", "warn", dkim.Details, "Replace weak, revoked or testing (t=y) DKIM keys with a 2048-bit key")
+	default:
+		set("dkim", "pass", dkim.Details, "")
+	}
+
+	// DMARC
+	dmarc := email.DMARC
+	switch {
+	case !dmarc.Configured:
+		set("dmarc", "fail", "No DMARC record", fmt.Sprintf("Publish _dmarc.%s with v=DMARC1; p=none; rua=mailto:<address>, then move to quarantine and reject", cleanDomain))
+	case !dmarc.Valid:
+		set("dmarc", "fail", dmarc.Details, fmt.Sprintf("Fix the DMARC record: %s", strings.Join(dmarc.Errors, "; ")))
+	case dmarc.EffectivePolicy == "none":
+		set("dmarc", "warn", "DMARC policy is p=none (monitoring only)", "Move DMARC to p=quarantine, then p=reject, once aggregate reports show every legitimate source aligned")
+	case dmarc.Percentage < 100:
+		set("dmarc", "warn", fmt.Sprintf("DMARC policy applies to pct=%d", dmarc.Percentage), "Raise DMARC pct to 100")
+	case len(dmarc.RUA) == 0:
+		set("dmarc", "warn", "DMARC is enforced but no aggregate reports are requested", "Add rua= to the DMARC record and review the reports with /api/v1/dmarc/reports")
+	default:
+		set("dmarc", "pass", dmarc.Details, "")
+	}
+
+	// MX reachability, reverse DNS and reputation
+	// Several MX names often share an address, so address checks count each IP once
+	var unreachable, noTLS, noFCrDNS, listed []string
+	seenIPs := make(map[string]bool)
+	for _, mx := range info.MXHosts {
+		switch {
+		case !mx.Reachable:
+			unreachable = append(unreachable, mx.Host)
+		case !mx.STARTTLS:
+			noTLS = append(noTLS, mx.Host)
+		}
+		if mx.IP == "" || seenIPs[mx.IP] {
+			continue
+		}
+		seenIPs[mx.IP] = true
+		if !mx.FCrDNS {
+			noFCrDNS = append(noFCrDNS, mx.IP)
+		}
+		if len(mx.Listings) > 0 {
+			listed = append(listed, fmt.Sprintf("%s (%s)", mx.IP, strings.Join(mx.Listings, ", ")))
+		}
+	}
+	switch {
+	case len(info.MXHosts) == 0:
+		set("mx", "fail", "No MX records", "Publish MX records so bounces and replies can be delivered")
+	case egressBlocked:
+		set("mx", "unknown", "No MX host answered on port 25; outbound port 25 is probably blocked from this server", "")
+	case len(unreachable) == len(info.MXHosts):
+		set("mx", "fail", "No MX host accepts connections on port 25", fmt.Sprintf("Make the MX hosts reachable on port 25: %s", strings.Join(unreachable, ", ")))
+	case len(unreachable) > 0 || len(noTLS) > 0:
+		set("mx", "warn", fmt.Sprintf("%d unreachable, %d without STARTTLS", len(unreachable), len(noTLS)),
+			fmt.Sprintf("Fix MX hosts that are unreachable or lack STARTTLS: %s", strings.Join(append(unreachable, noTLS...), ", ")))
+	default:
+		set("mx", "pass", fmt.Sprintf("All %d MX hosts accept connections with STARTTLS", len(info.MXHosts)), "")
+	}
+
+	addresses := len(seenIPs)
+	switch {
+	case addresses == 0:
+		set("ptr", "fail", "No MX addresses to check", "Publish MX records with public addresses")
+		set("dnsbl", "fail", "No MX addresses to check", "Publish MX records with public addresses")
+	default:
+		switch len(noFCrDNS) {
+		case 0:
+			set("ptr", "pass", "Every MX address has forward-confirmed reverse DNS", "")
+		case addresses:
+			set("ptr", "fail", "No MX address has forward-confirmed reverse DNS", fmt.Sprintf("Ask your provider to set PTR records that resolve back to %s", strings.Join(noFCrDNS, ", ")))
+		default:
+			set("ptr", "warn", fmt.Sprintf("%d of %d MX addresses lack forward-confirmed reverse DNS", len(noFCrDNS), addresses), fmt.Sprintf("Ask your provider to set PTR records that resolve back to %s", strings.Join(noFCrDNS, ", ")))
+		}
+		if len(listed) > 0 {
+			set("dnsbl", "fail", fmt.Sprintf("%d of %d MX addresses listed", len(listed), addresses), fmt.Sprintf("Find the cause and request delisting for %s", strings.Join(listed, "; ")))
+		} else {
+			set("dnsbl", "pass", "No MX address is listed on the configured DNSBLs", "")
+		}
+	}
+
+	// MTA-STS and TLS-RPT
+	mtaSTS := email.MTASTS
+	switch {
+	case !mtaSTS.Configured:
+		set("mta_sts", "fail", "No MTA-STS policy", "Publish an MTA-STS policy in testing mode together with a TLS-RPT record, then switch to enforce")
+	case !mtaSTS.Valid:
+		set("mta_sts", "fail", mtaSTS.Details, fmt.Sprintf("Fix the MTA-STS policy: %s", strings.Join(mtaSTS.Errors, "; ")))
+	case mtaSTS.Mode != "enforce":
+		set("mta_sts", "warn", fmt.Sprintf("MTA-STS policy is in %s mode", mtaSTS.Mode), "Switch the MTA-STS policy to mode: enforce")
+	case !email.TLSRPT.Valid:
+		set("mta_sts", "warn", "MTA-STS is enforced without TLS-RPT", fmt.Sprintf("Publish _smtp._tls.%s with v=TLSRPTv1; rua=mailto:<address> to hear about TLS failures", cleanDomain))
+	default:
+		set("mta_sts", "pass", mtaSTS.Details, "")
+	}
+
+	// BIMI
+	bimi := email.BIMI
+	switch {
+	case !bimi.Configured:
+		set("bimi", "fail", "No BIMI record", "Publish a BIMI record with an SVG Tiny PS logo once DMARC is at enforcement")
+	case !bimi.Valid:
+		set("bimi", "warn", bimi.Details, fmt.Sprintf("Fix BIMI: %s", strings.Join(bimi.Errors, "; ")))
+	default:
+		set("bimi", "pass", bimi.Details, "")
+	}
+
+	// Checks that could not be evaluated are left out of the score
+	points, possible := 0, 0
+	for _, name := range deliverabilityOrder {
+		check := checks[name]
+		switch check.Status {
+		case "pass":
+			check.Points = check.Weight
+		case "warn":
+			check.Points = check.Weight / 2
+		}
+		info.Checks = append(info.Checks, *check)
+		if check.Status == "unknown" {
+			continue
+		}
+		points += check.Points
+		possible += check.Weight
+
+		impact := check.Weight - check.Points
+		if impact == 0 {
+			continue
+		}
+		priority := "low"
+		switch {
+		case impact >= 10:
+			priority = "high"
+		case impact >= 5:
+			priority = "medium"
+		}
+		info.Recommendations = append(info.Recommendations, DeliverabilityRecommendation{
+			Priority: priority,
+			Check:    name,
+			Impact:   impact,
+			Action:   actions[name],
+		})
+	}
+	sort.SliceStable(info.Recommendations, func(i, j int) bool {
+		return info.Recommendations[i].Impact > info.Recommendations[j].Impact
+	})
+	if possible > 0 {
+		info.Score = (points*100 + possible/2) / possible
+	}
+
+	switch {
+	case info.Score >= 90:
+		info.Grade = "A"
+	case info.Score >= 75:
+		info.Grade = "B"
+	case info.Score >= 60:
+		info.Grade = "C"
+	case info.Score >= 45:
+		info.Grade = "D"
+	case info.Score >= 30:
+		info.Grade = "E"
+	default:
+		info.Grade = "F"
+	}
+
+	return info
+}
+
+// handleDeliverability handles the email deliverability report
+func handleDeliverability(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/deliverability", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	deliverabilityInfo := checker.CheckDeliverability(domain)
+	if ttl, ok := routeTTL["/api/v1/deliverability"]; ok {
+		apiCache.Set(key, deliverabilityInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    deliverabilityInfo,
+	})
+}
+
+// CombinedResult holds both SSL and WebSettings info from a single connection
+type CombinedResult struct {
+	SSL         SSLInfo
+	WebSettings WebSettingsInfo
+}
+
+// CheckSSLAndWebSettings performs both SSL and WebSettings checks using a single HTTP request
+func (nc *NetChecker) CheckSSLAndWebSettings(domain string, opts WebRequestOptions) CombinedResult {
+	var result CombinedResult
+
+	// Clean domain for HTTP request
+	fullURL := domain
+	if !strings.HasPrefix(domain, "https://") && !strings.HasPrefix(domain, "http://") {
+		fullURL = "https://" + domain
+	}
+
+	cleanDomain := strings.TrimPrefix(fullURL, "https://")
+	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
+	cleanDomain = strings.Split(cleanDomain, "/")[0]
+
+	if err := opts.checkTarget(fullURL); err != nil {
+		result.WebSettings.Domain = cleanDomain
+		result.WebSettings.Error = err.Error()
+		result.SSL.Error = result.WebSettings.Error
+		return result
+	}
+
+	// Make HTTP request and get certificate from TLS connection
+	start := time.Now()
+	req, err := opts.newRequest(fullURL)
+	if err != nil {
+		result.WebSettings.Error = fmt.Sprintf("Failed to create request: %v", err)
+		result.SSL.Error = result.WebSettings.Error
+		return result
+	}
+
+	resp, chain, err := nc.followRedirects(req, opts.FollowRedirects)
+	result.WebSettings.ResponseTime = time.Since(start).Milliseconds()
+	result.WebSettings.Domain = cleanDomain
+	result.WebSettings.Request = &opts
+	result.WebSettings.Redirects = chain
+
+	if len(chain.Hops) > 0 && chain.Hops[0].Location != "" {
+		result.WebSettings.RedirectURL = chain.Hops[0].Location
+	}
+
+	if err != nil {
+		result.WebSettings.Error = fmt.Sprintf("Failed to connect: %v", err)
+		result.SSL.Domain = cleanDomain
+		result.SSL.Error = result.WebSettings.Error
+		return result
+	}
+	defer resp.Body.Close()
+
+	// Extract WebSettings info
+	result.WebSettings.StatusCode = resp.StatusCode
+	result.WebSettings.Server = resp.Header.Get("Server")
+	result.WebSettings.ContentType = resp.Header.Get("Content-Type")
+	result.WebSettings.LastModified = resp.Header.Get("Last-Modified")
+	result.WebSettings.ETag = resp.Header.Get("ETag")
+	result.WebSettings.ContentLength = resp.ContentLength
+
+	// Parse HSTS header
+	hstsHeader := resp.Header.Get("Strict-Transport-Security")
+	result.WebSettings.HSTS = parseHSTSHeader(hstsHeader)
+	result.WebSettings.SecurityHeaders = AnalyzeSecurityHeaders(resp.Header, resp.Request.URL.Scheme == "https")
+
+	result.WebSettings.Headers = flattenHeaders(resp.Header)
+	result.WebSettings.Cookies = AuditCookies(chain)
+	var html bytes.Buffer
+	result.WebSettings.Timing = chain.finishTiming(io.TeeReader(resp.Body, &html))
+	result.WebSettings.Technologies = DetectTechnologies(resp.Header, cookieValues(result.WebSettings.Cookies), html.String())
+
+	// Extract SSL certificate from TLS connection state
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		cert := resp.TLS.PeerCertificates[0]
+		result.SSL.Domain = cleanDomain
+		result.SSL.Valid = true
+		result.SSL.Issuer = cert.Issuer.String()
+		result.SSL.Subject = cert.Subject.String()
+		result.SSL.NotBefore = cert.NotBefore
+		result.SSL.NotAfter = cert.NotAfter
+		result.SSL.DaysUntilExpiry = int(time.Until(cert.NotAfter).Hours() / 24)
+		result.SSL.SerialNumber = cert.SerialNumber.String()
+		result.SSL.SignatureAlg = cert.SignatureAlgorithm.String()
+		result.SSL.PublicKeyAlg = cert.PublicKeyAlgorithm.String()
+
+		switch pubKey := cert.PublicKey.(type) {
+		case *rsa.PublicKey:
+			result.SSL.KeySize = pubKey.N.BitLen()
+		default:
+			result.SSL.KeySize = 0
+		}
+	} else {
+		result.SSL.Domain = cleanDomain
+		result.SSL.Error = "No TLS certificate found"
+	}
+
+	return result
+}
+
+// API Handlers
+
+func handleSSL(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	// cache
+	key := cacheKey("/api/v1/ssl", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+
+	checker := NewNetChecker()
+	sslInfo := checker.CheckSSL(domain)
+	if ttl, ok := routeTTL["/api/v1/ssl"]; ok {
+		apiCache.Set(key, sslInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    sslInfo,
+	})
+}
+
+func handleHTTP3(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/http3", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	http3Info := checker.CheckHTTP3(domain)
+	if ttl, ok := routeTTL["/api/v1/http3"]; ok {
+		apiCache.Set(key, http3Info, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    http3Info,
+	})
+}
+
+func handleDNS(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+	key := cacheKey("/api/v1/dns", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	dnsInfo := checker.CheckDNS(domain)
+	if ttl, ok := routeTTL["/api/v1/dns"]; ok {
+		apiCache.Set(key, dnsInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    dnsInfo,
+	})
+}
+
+func handleIP(c *gin.Context) {
+	// Accept both 'ip' and 'domain' parameters for flexibility
+	input := c.Query("ip")
+	if input == "" {
+		input = c.Query("domain")
+	}
+
+	if input == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "IP or domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/ip", map[string]string{"input": input})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	ipInfo := checker.CheckIP(input)
+	if ttl, ok := routeTTL["/api/v1/ip"]; ok {
+		apiCache.Set(key, ipInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    ipInfo,
+	})
+}
+
+func handleMyIP(c *gin.Context) {
+	// Get the real client IP from the request
+	clientIP := getRealClientIP(c)
+
+	if clientIP == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Could not determine client IP address",
+		})
+		return
+	}
+
+	// Use client IP as cache key (per user)
+	key := cacheKey("/api/v1/my-ip", map[string]string{"ip": clientIP})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+
+	// Get geolocation information from MaxMind
+	country, region, city, isp, organization, timezone := lookupGeoIP(clientIP)
+
+	// Create IP info response directly from the client IP, no resolution needed
+	ipInfo := IPInfo{
+		Input:        "Your IP: " + clientIP,
+		IsDomain:     false,
+		IP:           clientIP,
+		Country:      country,
+		Region:       region,
+		City:         city,
+		ISP:          isp,
+		Organization: organization,
+		Timezone:     timezone,
+	}
+
+	if ttl, ok := routeTTL["/api/v1/my-ip"]; ok {
+		apiCache.Set(key, ipInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    ipInfo,
+	})
+}
+
+// -----------------------------
+// Bulk IP / CIDR lookup
+// -----------------------------
+
+// maxBulkIPs caps the number of addresses a single bulk request may expand to
+const maxBulkIPs = 4096
+
+// maxBulkBodyBytes caps the size of a bulk request body
+const maxBulkBodyBytes = 2 << 20
+
+// BulkIPRequest represents a JSON bulk lookup request
+type BulkIPRequest struct {
+	Inputs []string `json:"inputs"`
+}
+
+// BulkIPSummary aggregates the results of a bulk lookup
+type BulkIPSummary struct {
+	Total     int            `json:"total"`
+	Invalid   []string       `json:"invalid,omitempty"`
+	Truncated bool           `json:"truncated"`
+	ByCountry map[string]int `json:"by_country"`
+	ByASN     map[string]int `json:"by_asn"`
+	ByPrefix  map[string]int `json:"by_prefix"`
+}
+
+// BulkIPLine is a single NDJSON line of the bulk lookup stream
+type BulkIPLine struct {
+	Type string      `json:"type"` // ip, summary
+	Data interface{} `json:"data"`
+}
+
+// extractBulkTokens pulls candidate IP and CIDR tokens out of free-form text such as firewall logs
+func extractBulkTokens(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		switch {
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'f', r >= 'A' && r <= 'F':
+			return false
+		case r == '.' || r == ':' || r == '/' || r == '[' || r == ']':
+			return false
+		}
+		return true
+	})
+}
+
+// stripBulkPort removes a trailing port from "1.2.3.4:443" and "[2001:db8::1]:443"
+// style entries, as found in firewall and access logs.
+func stripBulkPort(entry string) string {
+	host, port, err := net.SplitHostPort(entry)
+	if err != nil {
+		return entry
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return entry
+	}
+	return host
+}
+
+// expandBulkInputs expands IPs and CIDR blocks into a de-duplicated address list.
+// Unparseable entries are returned separately when reportInvalid is set.
+func expandBulkInputs(inputs []string, limit int, reportInvalid bool) (ips []string, invalid []string, truncated bool) {
+	seen := make(map[string]bool)
+	add := func(ip string) bool {
+		if seen[ip] {
+			return true
+		}
+		if len(ips) >= limit {
+			truncated = true
+			return false
+		}
+		seen[ip] = true
+		ips = append(ips, ip)
+		return true
+	}
+
+	for _, raw := range inputs {
+		entry := strings.Trim(stripBulkPort(strings.Trim(strings.TrimSpace(raw), "\"'")), "[]")
+		if entry == "" {
+			continue
+		}
+
+		if ip := net.ParseIP(entry); ip != nil {
+			if !add(ip.String()) {
+				break
+			}
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			if reportInvalid {
+				invalid = append(invalid, entry)
+			}
+			continue
+		}
+
+		ip := make(net.IP, len(network.IP))
+		copy(ip, network.IP)
+		for ; network.Contains(ip); incrementIP(ip) {
+			if !add(ip.String()) {
+				break
+			}
+		}
+		if truncated {
+			break
+		}
+	}
+
+	return ips, invalid, truncated
+}
+
+// incrementIP advances an IP address in place by one
+func incrementIP(ip net.IP) {
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			return
+		}
+	}
+}
+
+// ipPrefixKey returns the /24 (IPv4) or /48 (IPv6) block an address belongs to
+func ipPrefixKey(ipAddr string) string {
+	ip := net.ParseIP(ipAddr)
+	if ip == nil {
+		return ""
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// lookupIPInfo builds IPInfo for an address from the local GeoIP databases only
+func lookupIPInfo(ipAddr string) IPInfo {
+	info := IPInfo{Input: ipAddr, IP: ipAddr}
+	info.Country, info.Region, info.City, info.ISP, info.Organization, info.Timezone = lookupGeoIP(ipAddr)
+	info.ASN, _ = lookupASN(ipAddr)
+	return info
+}
+
+func handleIPBulk(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBulkBodyBytes)
+
+	var inputs []string
+	reportInvalid := false
+	if strings.HasPrefix(c.ContentType(), "application/json") {
+		var req BulkIPRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid request body: %v", err),
+			})
+			return
+		}
+		inputs = req.Inputs
+		reportInvalid = true
+	} else {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to read request body: %v", err),
+			})
+			return
+		}
+		inputs = extractBulkTokens(string(body))
+	}
+
+	ips, invalid, truncated := expandBulkInputs(inputs, maxBulkIPs, reportInvalid)
+	if len(ips) == 0 {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "No valid IP addresses or CIDR blocks found",
+		})
+		return
+	}
+
+	summary := BulkIPSummary{
+		Invalid:   invalid,
+		Truncated: truncated,
+		ByCountry: make(map[string]int),
+		ByASN:     make(map[string]int),
+		ByPrefix:  make(map[string]int),
+	}
+
+	// Stream one line per IP so large batches render progressively
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	for _, ip := range ips {
+		info := lookupIPInfo(ip)
+		summary.Total++
+		if info.Country != "" {
+			summary.ByCountry[info.Country]++
+		} else {
+			summary.ByCountry["Unknown"]++
+		}
+		if info.ASN != 0 {
+			summary.ByASN[fmt.Sprintf("AS%d %s", info.ASN, info.Organization)]++
+		} else {
+			summary.ByASN["Unknown"]++
+		}
+		summary.ByPrefix[ipPrefixKey(ip)]++
+
+		if err := enc.Encode(BulkIPLine{Type: "ip", Data: info}); err != nil {
+			return
+		}
+		c.Writer.Flush()
+	}
+
+	enc.Encode(BulkIPLine{Type: "summary", Data: summary})
+	c.Writer.Flush()
+}
+
+func handleWebSettings(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	opts, err := parseWebRequestOptions(c, "GET")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid request options: %v", err),
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/web-settings", map[string]string{"domain": domain, "request": opts.cacheKey()})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	webInfo := checker.CheckWebSettings(domain, opts)
+	if ttl, ok := routeTTL["/api/v1/web-settings"]; ok {
+		apiCache.Set(key, webInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    webInfo,
+	})
+}
+
+func handleEmailConfig(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	// Probing the MX hosts on port 25 is slow, so it is opt-in
+	probeMX := c.Query("probe_mx") == "true"
+	key := cacheKey("/api/v1/email-config", map[string]string{"domain": domain, "probe_mx": strconv.FormatBool(probeMX)})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	emailInfo := checker.CheckEmailConfig(domain, probeMX)
+	if ttl, ok := routeTTL["/api/v1/email-config"]; ok {
+		apiCache.Set(key, emailInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    emailInfo,
+	})
+}
+
+// DNS servers with blocklists
+var blocklistDNSServers = []DNSServer{
+	{Name: "AdGuard", IP: "176.103.130.130"},
+	{Name: "AdGuard Family", IP: "176.103.130.132"},
+	{Name: "CleanBrowsing Adult", IP: "185.228.168.10"},
+	{Name: "CleanBrowsing Family", IP: "185.228.168.168"},
+	{Name: "CleanBrowsing Security", IP: "185.228.168.9"},
+	{Name: "CloudFlare", IP: "1.1.1.1"},
+	{Name: "CloudFlare Family", IP: "1.1.1.3"},
+	{Name: "Comodo Secure", IP: "8.26.56.26"},
+	{Name: "Google DNS", IP: "8.8.8.8"},
+	{Name: "Neustar Family", IP: "156.154.70.3"},
+	{Name: "Neustar Protection", IP: "156.154.70.2"},
+	{Name: "Norton Family", IP: "199.85.126.20"},
+	{Name: "OpenDNS", IP: "208.67.222.222"},
+	{Name: "OpenDNS Family", IP: "208.67.222.123"},
+	{Name: "Quad9", IP: "9.9.9.9"},
+	{Name: "Yandex Family", IP: "77.88.8.7"},
+	{Name: "Yandex Safe", IP: "77.88.8.88"},
+}
+
+// Known block IPs that DNS servers return when blocking domains
+var knownBlockIPs = []string{
+	"146.112.61.106", // OpenDNS
+	"185.228.168.10", // CleanBrowsing
+	"8.26.56.26",     // Comodo
+	"208.69.38.170",  // OpenDNS
+	"208.69.39.170",  // OpenDNS
+	"208.67.222.222", // OpenDNS
+	"208.67.222.123", // OpenDNS FamilyShield
+	"199.85.126.10",  // Norton
+	"199.85.126.20",  // Norton Family
+	"156.154.70.22",  // Neustar
+	"77.88.8.7",      // Yandex
+}
+
+// contains checks if a string slice contains a value
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// isDomainBlockedByServer checks if a domain is blocked by a specific DNS server
+func (nc *NetChecker) isDomainBlockedByServer(domain, serverIP string) bool {
+	// Create a custom DNS resolver for the specific server
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Try to resolve the domain using the server
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			d := net.Dialer{}
+			return d.DialContext(ctx, "udp", serverIP+":53")
+		},
+	}
+
+	// Resolve A records
+	addrs, err := resolver.LookupIPAddr(ctx, domain)
+	if err != nil {
+		// If DNS resolution fails, it might indicate the domain is blocked
+		return true
+	}
+
+	// Check if any returned IP is a known block IP
+	for _, addr := range addrs {
+		if contains(knownBlockIPs, addr.IP.String()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckBlocklist checks if a domain is blocked by various DNS servers
+func (nc *NetChecker) CheckBlocklist(domain string) BlocklistInfo {
+	info := BlocklistInfo{Domain: domain}
+
+	// Clean domain
+	cleanDomain := strings.TrimPrefix(domain, "https://")
+	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
+	cleanDomain = strings.Split(cleanDomain, "/")[0]
+
+	var results []BlocklistResult
+
+	// Check against each DNS server
+	for _, server := range blocklistDNSServers {
+		isBlocked := nc.isDomainBlockedByServer(cleanDomain, server.IP)
+		results = append(results, BlocklistResult{
+			Server:    server.Name,
+			ServerIP:  server.IP,
+			IsBlocked: isBlocked,
+		})
+	}
+
+	info.Results = results
+	return info
+}
+
+func handleBlocklist(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/blocklist", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	blocklistInfo := checker.CheckBlocklist(domain)
+	if ttl, ok := routeTTL["/api/v1/blocklist"]; ok {
+		apiCache.Set(key, blocklistInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    blocklistInfo,
+	})
+}
+
+func handleHSTS(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	checker := NewNetChecker()
+
+	// Get web settings which includes HSTS
+	webInfo := checker.CheckWebSettings(domain, defaultWebRequest)
+
+	// Return just the HSTS info
+	hstsInfo := HSTSInfo{
+		Enabled:           webInfo.HSTS.Enabled,
+		MaxAge:            webInfo.HSTS.MaxAge,
+		IncludeSubDomains: webInfo.HSTS.IncludeSubDomains,
+		Preload:           webInfo.HSTS.Preload,
+		Directive:         webInfo.HSTS.Directive,
+		Details:           webInfo.HSTS.Details,
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"domain": domain,
+			"hsts":   hstsInfo,
+		},
+	})
+}
+
+// -----------------------------
+// Security headers analysis
+// -----------------------------
+
+// SecurityHeaderFinding represents the evaluation of a single response header
+type SecurityHeaderFinding struct {
+	Header  string   `json:"header"`
+	Present bool     `json:"present"`
+	Value   string   `json:"value,omitempty"`
+	Status  string   `json:"status"` // pass, warn, fail, info
+	Issues  []string `json:"issues,omitempty"`
+}
+
+// SecurityHeadersInfo represents the security header grade of a response
+type SecurityHeadersInfo struct {
+	Grade    string                  `json:"grade"`
+	Score    int                     `json:"score"`
+	Findings []SecurityHeaderFinding `json:"findings"`
+}
+
+// securityHeaderWeights are the points each header contributes to the 100 point score
+var securityHeaderWeights = map[string]int{
+	"Content-Security-Policy":      25,
+	"Strict-Transport-Security":    20,
+	"X-Frame-Options":              10,
+	"X-Content-Type-Options":       10,
+	"Referrer-Policy":              10,
+	"Permissions-Policy":           10,
+	"Cross-Origin-Opener-Policy":   5,
+	"Cross-Origin-Embedder-Policy": 5,
+	"Cross-Origin-Resource-Policy": 5,
+}
+
+// deprecatedSecurityHeaders are headers browsers ignore or that do more harm than good
+var deprecatedSecurityHeaders = []struct {
+	name  string
+	issue string
+}{
+	{"X-XSS-Protection", "X-XSS-Protection is deprecated; the XSS auditor was removed from browsers. Use Content-Security-Policy and set this to 0 or remove it"},
+	{"Expect-CT", "Expect-CT is deprecated; Certificate Transparency is enforced by browsers by default"},
+	{"Public-Key-Pins", "HTTP Public Key Pinning is deprecated and can lock users out of the site"},
+	{"Feature-Policy", "Feature-Policy was replaced by Permissions-Policy"},
+	{"X-Download-Options", "X-Download-Options only affects legacy Internet Explorer"},
+	{"X-Content-Security-Policy", "X-Content-Security-Policy is obsolete, use Content-Security-Policy"},
+	{"X-Webkit-CSP", "X-Webkit-CSP is obsolete, use Content-Security-Policy"},
+}
+
+// parseCSP splits a Content-Security-Policy value into directives and their source lists
+func parseCSP(policy string) map[string][]string {
+	directives := make(map[string][]string)
+	for _, part := range strings.Split(policy, ";") {
+		fields := strings.Fields(strings.TrimSpace(part))
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		// Only the first occurrence of a directive is honored
+		if _, exists := directives[name]; !exists {
+			directives[name] = fields[1:]
+		}
+	}
+	return directives
+}
+
+// cspWildcardDirectives are directives where allowing any origin defeats the policy
+var cspWildcardDirectives = []string{"default-src", "object-src", "frame-src", "worker-src", "base-uri", "form-action", "frame-ancestors"}
+
+// cspFetchDirectives fall back to default-src when they are not set
+var cspFetchDirectives = map[string]bool{"script-src": true, "object-src": true, "frame-src": true, "worker-src": true}
+
+// versionDisclosureRegex matches version numbers in server identification headers
+var versionDisclosureRegex = regexp.MustCompile(`\d+\.\d+`)
+
+// splitCSPPolicies returns every policy delivered in Content-Security-Policy headers.
+// A header may carry several comma separated policies, and browsers enforce all of them.
+func splitCSPPolicies(values []string) []string {
+	var policies []string
+	for _, value := range values {
+		for _, policy := range strings.Split(value, ",") {
+			if strings.TrimSpace(policy) != "" {
+				policies = append(policies, strings.TrimSpace(policy))
+			}
+		}
+	}
+	return policies
+}
+
+// cspSources returns the source list that governs a directive, following the
+// default-src fallback for fetch directives
+func cspSources(directives map[string][]string, name string) ([]string, bool) {
+	if sources, ok := directives[name]; ok {
+		return sources, true
+	}
+	if cspFetchDirectives[name] {
+		sources, ok := directives["default-src"]
+		return sources, ok
+	}
+	return nil, false
+}
+
+// cspPolicyIssues lists the weaknesses of a single policy by the directive they concern.
+// Issues about a missing directive use an empty directive name.
+func cspPolicyIssues(directives map[string][]string) map[string]string {
+	issues := make(map[string]string)
+
+	if scriptSrc, ok := cspSources(directives, "script-src"); ok {
+		hasNonceOrHash := false
+		for _, src := range scriptSrc {
+			lower := strings.ToLower(src)
+			if strings.HasPrefix(lower, "'nonce-") || strings.HasPrefix(lower, "'sha256-") ||
+				strings.HasPrefix(lower, "'sha384-") || strings.HasPrefix(lower, "'sha512-") {
+				hasNonceOrHash = true
+			}
+		}
+		for _, src := range scriptSrc {
+			switch strings.ToLower(src) {
+			case "'unsafe-inline'":
+				// Ignored by CSP2+ browsers when a nonce or hash is present
+				if !hasNonceOrHash {
+					issues["script-src allows 'unsafe-inline'"] = "script-src"
+				}
+			case "'unsafe-eval'":
+				issues["script-src allows 'unsafe-eval'"] = "script-src"
+			case "*":
+				issues["script-src allows any origin (*)"] = "script-src"
+			case "http:", "https:":
+				issues[fmt.Sprintf("script-src allows any %s origin", strings.TrimSuffix(strings.ToLower(src), ":"))] = "script-src"
+			case "data:":
+				issues["script-src allows data: URIs"] = "script-src"
+			}
+		}
+	}
+
+	// Inherited wildcards are already reported against default-src
+	for _, name := range cspWildcardDirectives {
+		for _, src := range directives[name] {
+			switch strings.ToLower(src) {
+			case "*":
+				issues[fmt.Sprintf("%s allows any origin (*)", name)] = name
+			case "http:", "https:":
+				issues[fmt.Sprintf("%s allows any %s origin", name, strings.TrimSuffix(strings.ToLower(src), ":"))] = name
+			}
+		}
+	}
+
+	if _, ok := directives["default-src"]; !ok {
+		issues["Missing default-src directive"] = ""
+	}
+	if _, ok := directives["object-src"]; !ok {
+		if def := directives["default-src"]; !(len(def) == 1 && def[0] == "'none'") {
+			issues["Missing object-src directive (plugins are not restricted)"] = ""
+		}
+	}
+	if _, ok := directives["base-uri"]; !ok {
+		issues["Missing base-uri directive"] = ""
+	}
+	if _, ok := directives["frame-ancestors"]; !ok {
+		issues["Missing frame-ancestors directive"] = ""
+	}
+	return issues
+}
+
+// evaluateCSP checks the enforced Content-Security-Policy policies for common weaknesses.
+// A resource must be allowed by every policy, so a weakness only counts when each
+// policy that restricts the directive has it, and a directive is only missing when no
+// policy sets it.
+func evaluateCSP(policies []string) (status string, issues []string) {
+	parsed := make([]map[string][]string, len(policies))
+	found := make([]map[string]string, len(policies))
+	counts := make(map[string]int)
+	for i, policy := range policies {
+		parsed[i] = parseCSP(policy)
+		found[i] = cspPolicyIssues(parsed[i])
+		for issue := range found[i] {
+			counts[issue]++
+		}
+	}
+
+	for _, policyIssues := range found {
+		for issue, directive := range policyIssues {
+			want := len(policies)
+			if directive != "" {
+				want = 0
+				for _, directives := range parsed {
+					if _, ok := cspSources(directives, directive); ok {
+						want++
+					}
+				}
+			}
+			if counts[issue] == want && !contains(issues, issue) {
+				issues = append(issues, issue)
+			}
+		}
+	}
+	sort.Strings(issues)
+
+	if len(issues) > 0 {
+		return "warn", issues
+	}
+	return "pass", nil
+}
+
+// evaluateReferrerPolicy checks a Referrer-Policy value; the last recognized token wins
+func evaluateReferrerPolicy(value string) (string, []string) {
+	policy := ""
+	for _, token := range strings.Split(value, ",") {
+		token = strings.ToLower(strings.TrimSpace(token))
+		switch token {
+		case "no-referrer", "no-referrer-when-downgrade", "origin", "origin-when-cross-origin",
+			"same-origin", "strict-origin", "strict-origin-when-cross-origin", "unsafe-url":
+			policy = token
+		}
+	}
+	switch policy {
+	case "":
+		return "fail", []string{"No recognized referrer policy"}
+	case "unsafe-url":
+		return "warn", []string{"unsafe-url leaks full URLs, including paths and query strings, to all origins"}
+	case "no-referrer-when-downgrade":
+		return "warn", []string{"no-referrer-when-downgrade leaks full URLs to other HTTPS origins"}
+	}
+	return "pass", nil
+}
+
+// evaluatePermissionsPolicy flags powerful features that are granted to every origin
+func evaluatePermissionsPolicy(value string) (string, []string) {
+	var issues []string
+	sensitive := map[string]bool{"camera": true, "microphone": true, "geolocation": true, "payment": true, "usb": true, "display-capture": true}
+	for _, part := range strings.Split(value, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		feature := strings.ToLower(strings.TrimSpace(kv[0]))
+		allowlist := strings.TrimSpace(kv[1])
+		if sensitive[feature] && (allowlist == "*" || strings.Contains(allowlist, "*")) {
+			issues = append(issues, fmt.Sprintf("%s is allowed for all origins", feature))
+		}
+	}
+	if len(issues) > 0 {
+		return "warn", issues
+	}
+	return "pass", nil
+}
+
+// AnalyzeSecurityHeaders evaluates security-related response headers and assigns a letter grade
+func AnalyzeSecurityHeaders(header http.Header, isHTTPS bool) SecurityHeadersInfo {
+	info := SecurityHeadersInfo{}
+	score := 0
+	possible := 0
+	hasWarnings := false
+
+	add := func(name string, finding SecurityHeaderFinding) {
+		finding.Header = name
+		weight := securityHeaderWeights[name]
+		possible += weight
+		switch finding.Status {
+		case "pass", "info":
+			score += weight
+		case "warn":
+			score += weight / 2
+			hasWarnings = true
+		}
+		info.Findings = append(info.Findings, finding)
+	}
+
+	// Content-Security-Policy
+	cspPolicies := splitCSPPolicies(header.Values("Content-Security-Policy"))
+	csp := strings.Join(cspPolicies, ", ")
+	hasFrameAncestors := false
+	for _, policy := range cspPolicies {
+		if _, ok := parseCSP(policy)["frame-ancestors"]; ok {
+			hasFrameAncestors = true
+		}
+	}
+	f := SecurityHeaderFinding{Present: csp != "", Value: csp}
+	if csp == "" {
+		f.Status = "fail"
+		f.Issues = []string{"Content-Security-Policy header not present"}
+		if ro := header.Get("Content-Security-Policy-Report-Only"); ro != "" {
+			f.Status = "warn"
+			f.Issues = []string{"Only Content-Security-Policy-Report-Only is set; the policy is not enforced"}
+		}
+	} else {
+		f.Status, f.Issues = evaluateCSP(cspPolicies)
+	}
+	add("Content-Security-Policy", f)
+
+	// Strict-Transport-Security
+	hsts := parseHSTSHeader(header.Get("Strict-Transport-Security"))
+	f = SecurityHeaderFinding{Present: hsts.Enabled, Value: hsts.Directive}
+	switch {
+	case !isHTTPS:
+		f.Status = "fail"
+		f.Issues = []string{"Site is not served over HTTPS"}
+	case !hsts.Enabled:
+		f.Status = "fail"
+		f.Issues = []string{"Strict-Transport-Security header not present"}
+	case hsts.MaxAge < 15552000:
+		f.Status = "warn"
+		f.Issues = []string{fmt.Sprintf("max-age of %d seconds is below the recommended 6 months", hsts.MaxAge)}
+	default:
+		f.Status = "pass"
+	}
+	add("Strict-Transport-Security", f)
+
+	// X-Frame-Options (superseded by CSP frame-ancestors)
+	xfo := header.Get("X-Frame-Options")
+	f = SecurityHeaderFinding{Present: xfo != "", Value: xfo}
+	switch strings.ToUpper(strings.TrimSpace(xfo)) {
+	case "DENY", "SAMEORIGIN":
+		f.Status = "pass"
+	case "":
+		if hasFrameAncestors {
+			f.Status = "info"
+			f.Issues = []string{"Not set, but framing is controlled by CSP frame-ancestors"}
+		} else {
+			f.Status = "fail"
+			f.Issues = []string{"X-Frame-Options header not present and no CSP frame-ancestors; page can be framed (clickjacking)"}
+		}
+	default:
+		if strings.HasPrefix(strings.ToUpper(xfo), "ALLOW-FROM") {
+			f.Status = "warn"
+			f.Issues = []string{"ALLOW-FROM is not supported by modern browsers, use CSP frame-ancestors"}
+		} else {
+			f.Status = "fail"
+			f.Issues = []string{fmt.Sprintf("Invalid value %q", xfo)}
+		}
+		if hasFrameAncestors {
+			f.Status = "info"
+		}
+	}
+	add("X-Frame-Options", f)
+
+	// X-Content-Type-Options
+	xcto := header.Get("X-Content-Type-Options")
+	f = SecurityHeaderFinding{Present: xcto != "", Value: xcto}
+	switch {
+	case strings.EqualFold(strings.TrimSpace(xcto), "nosniff"):
+		f.Status = "pass"
+	case xcto == "":
+		f.Status = "fail"
+		f.Issues = []string{"X-Content-Type-Options header not present"}
+	default:
+		f.Status = "fail"
+		f.Issues = []string{fmt.Sprintf("Invalid value %q, expected nosniff", xcto)}
+	}
+	add("X-Content-Type-Options", f)
+
+	// Referrer-Policy
+	rp := header.Get("Referrer-Policy")
+	f = SecurityHeaderFinding{Present: rp != "", Value: rp}
+	if rp == "" {
+		f.Status = "warn"
+		f.Issues = []string{"Referrer-Policy header not present; browsers default to strict-origin-when-cross-origin"}
+	} else {
+		f.Status, f.Issues = evaluateReferrerPolicy(rp)
+	}
+	add("Referrer-Policy", f)
+
+	// Permissions-Policy
+	pp := header.Get("Permissions-Policy")
+	f = SecurityHeaderFinding{Present: pp != "", Value: pp}
+	if pp == "" {
+		f.Status = "fail"
+		f.Issues = []string{"Permissions-Policy header not present"}
+	} else {
+		f.Status, f.Issues = evaluatePermissionsPolicy(pp)
+	}
+	add("Permissions-Policy", f)
+
+	// Cross-origin isolation headers
+	crossOrigin := []struct {
+		name string
+		good []string
+	}{
+		{"Cross-Origin-Opener-Policy", []string{"same-origin", "same-origin-allow-popups", "noopener-allow-popups"}},
+		{"Cross-Origin-Embedder-Policy", []string{"require-corp", "credentialless"}},
+		{"Cross-Origin-Resource-Policy", []string{"same-origin", "same-site", "cross-origin"}},
+	}
+	for _, co := range crossOrigin {
+		value := header.Get(co.name)
+		f = SecurityHeaderFinding{Present: value != "", Value: value}
+		token := strings.ToLower(strings.TrimSpace(strings.Split(value, ";")[0]))
+		switch {
+		case value == "":
+			f.Status = "warn"
+			f.Issues = []string{co.name + " header not present"}
+		case contains(co.good, token):
+			f.Status = "pass"
+		case token == "unsafe-none":
+			f.Status = "warn"
+			f.Issues = []string{"unsafe-none disables the protection"}
+		default:
+			f.Status = "fail"
+			f.Issues = []string{fmt.Sprintf("Invalid value %q", value)}
+		}
+		add(co.name, f)
+	}
+
+	// Deprecated headers cost points when present
+	penalty := 0
+	for _, dep := range deprecatedSecurityHeaders {
+		value := header.Get(dep.name)
+		if value == "" {
+			continue
+		}
+		if dep.name == "X-XSS-Protection" && strings.TrimSpace(value) == "0" {
+			continue
+		}
+		info.Findings = append(info.Findings, SecurityHeaderFinding{
+			Header:  dep.name,
+			Present: true,
+			Value:   value,
+			Status:  "warn",
+			Issues:  []string{dep.issue},
+		})
+		penalty += 5
+		hasWarnings = true
+	}
+
+	// Version disclosure
+	for _, name := range []string{"Server", "X-Powered-By", "X-AspNet-Version", "X-AspNetMvc-Version"} {
+		value := header.Get(name)
+		if value != "" && versionDisclosureRegex.MatchString(value) {
+			info.Findings = append(info.Findings, SecurityHeaderFinding{
+				Header:  name,
+				Present: true,
+				Value:   value,
+				Status:  "info",
+				Issues:  []string{"Discloses software version"},
+			})
+		}
+	}
+
+	info.Score = score * 100 / possible
+	info.Score -= penalty
+	if info.Score < 0 {
+		info.Score = 0
+	}
+
+	switch {
+	case info.Score >= 95 && !hasWarnings:
+		info.Grade = "A+"
+	case info.Score >= 90:
+		info.Grade = "A"
+	case info.Score >= 75:
+		info.Grade = "B"
+	case info.Score >= 60:
+		info.Grade = "C"
+	case info.Score >= 45:
+		info.Grade = "D"
+	case info.Score >= 30:
+		info.Grade = "E"
+	default:
+		info.Grade = "F"
+	}
+
+	return info
+}
+
+func handleSecurityHeaders(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/security-headers", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+
+	// Get web settings which includes the security header analysis
+	webInfo := checker.CheckWebSettings(domain, defaultWebRequest)
+	if webInfo.Error != "" {
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"domain": domain,
+				"error":  webInfo.Error,
+			},
+		})
+		return
+	}
+
+	data := map[string]interface{}{
+		"domain":           domain,
+		"final_url":        webInfo.Redirects.FinalURL,
+		"security_headers": webInfo.SecurityHeaders,
+	}
+	if ttl, ok := routeTTL["/api/v1/security-headers"]; ok {
+		apiCache.Set(key, data, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// -----------------------------
+// Compression and caching analysis
+// -----------------------------
+
+// compressionEncodings are offered one at a time to find which the server supports
+var compressionEncodings = []string{"gzip", "br", "zstd", "deflate"}
+
+// EncodingResult represents the response to a single Accept-Encoding offer
+type EncodingResult struct {
+	Encoding        string  `json:"encoding"`
+	Supported       bool    `json:"supported"`
+	ContentEncoding string  `json:"content_encoding,omitempty"`
+	Status          int     `json:"status"`
+	Bytes           int64   `json:"bytes"`
+	SavingsPercent  float64 `json:"savings_percent,omitempty"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// CompressionInfo represents the compression support of a URL
+type CompressionInfo struct {
+	UncompressedBytes int64            `json:"uncompressed_bytes"`
+	Supported         []string         `json:"supported"`
+	Encodings         []EncodingResult `json:"encodings"`
+	Issues            []string         `json:"issues,omitempty"`
+}
+
+// RevalidationResult represents a conditional request made with a cached validator
+type RevalidationResult struct {
+	Header      string `json:"header"`
+	Value       string `json:"value"`
+	Status      int    `json:"status"`
+	NotModified bool   `json:"not_modified"`
+	Error       string `json:"error,omitempty"`
+}
+
+// CachingInfo represents HTTP caching behaviour of a URL
+type CachingInfo struct {
+	CacheControl             string              `json:"cache_control,omitempty"`
+	Directives               map[string]string   `json:"directives,omitempty"`
+	Expires                  string              `json:"expires,omitempty"`
+	ETag                     string              `json:"etag,omitempty"`
+	WeakETag                 bool                `json:"weak_etag"`
+	LastModified             string              `json:"last_modified,omitempty"`
+	Vary                     []string            `json:"vary,omitempty"`
+	Age                      string              `json:"age,omitempty"`
+	Cacheable                bool                `json:"cacheable"`
+	FreshnessSeconds         int                 `json:"freshness_seconds"`
+	ETagRevalidation         *RevalidationResult `json:"etag_revalidation,omitempty"`
+	LastModifiedRevalidation *RevalidationResult `json:"last_modified_revalidation,omitempty"`
+	Issues                   []string            `json:"issues,omitempty"`
+}
+
+// CompressionCachingInfo represents compression and caching analysis for a URL
+type CompressionCachingInfo struct {
+	URL         string          `json:"url"`
+	FinalURL    string          `json:"final_url"`
+	Compression CompressionInfo `json:"compression"`
+	Caching     CachingInfo     `json:"caching"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// fetchRaw performs a single request without following redirects and returns the
+// response with its raw (still encoded) body. Connections are limited to addresses
+// the allowed check accepts.
+func (nc *NetChecker) fetchRaw(method, targetURL string, header http.Header, allowed func(net.IP) bool) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(method, targetURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", "NetCheck-API/1.0")
+
+	// Targets such as a redirect's final URL come from remote servers, so every
+	// connection is checked against the SSRF guard
+	client := nc.guardedHTTPClient(allowed)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTimedBodyBytes))
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, body, nil
+}
+
+// parseCacheControl splits a Cache-Control header into lower-cased directives
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		name := strings.ToLower(strings.TrimSpace(kv[0]))
+		val := ""
+		if len(kv) == 2 {
+			val = strings.Trim(strings.TrimSpace(kv[1]), "\"")
+		}
+		directives[name] = val
+	}
+	return directives
+}
+
+// revalidate issues a conditional GET with a validator and checks for 304
+func (nc *NetChecker) revalidate(targetURL, header, value string) *RevalidationResult {
+	result := &RevalidationResult{Header: header, Value: value}
+	resp, _, err := nc.fetchRaw("GET", targetURL, http.Header{header: []string{value}, "Accept-Encoding": []string{"identity"}}, isPublicIP)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = resp.StatusCode
+	result.NotModified = resp.StatusCode == http.StatusNotModified
+	return result
+}
+
+// analyzeCaching evaluates caching headers and tests conditional revalidation
+func (nc *NetChecker) analyzeCaching(targetURL string, resp *http.Response) CachingInfo {
+	info := CachingInfo{
+		CacheControl: resp.Header.Get("Cache-Control"),
+		Expires:      resp.Header.Get("Expires"),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Age:          resp.Header.Get("Age"),
+	}
+	info.WeakETag = strings.HasPrefix(info.ETag, "W/")
+	for _, v := range resp.Header.Values("Vary") {
+		for _, field := range strings.Split(v, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				info.Vary = append(info.Vary, field)
+			}
+		}
+	}
+
+	info.Directives = parseCacheControl(info.CacheControl)
+	_, noStore := info.Directives["no-store"]
+	_, noCache := info.Directives["no-cache"]
+	_, isPrivate := info.Directives["private"]
+
+	// Freshness lifetime per RFC 9111 section 4.2.1 (shared-cache view)
+	freshness := -1
+	if v, ok := info.Directives["s-maxage"]; ok {
+		freshness, _ = strconv.Atoi(v)
+	} else if v, ok := info.Directives["max-age"]; ok {
+		freshness, _ = strconv.Atoi(v)
+	} else if info.Expires != "" {
+		if exp, err := http.ParseTime(info.Expires); err == nil {
+			date := time.Now()
+			if d, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+				date = d
+			}
+			freshness = int(exp.Sub(date).Seconds())
+		} else {
+			info.Issues = append(info.Issues, fmt.Sprintf("Invalid Expires value %q is treated as already expired", info.Expires))
+			freshness = 0
+		}
+	}
+	if freshness < 0 {
+		freshness = 0
+		if info.CacheControl == "" && info.Expires == "" {
+			info.Issues = append(info.Issues, "No Cache-Control or Expires header; caches fall back to heuristic freshness")
+		}
+	}
+	info.FreshnessSeconds = freshness
+	info.Cacheable = !noStore && !contains(info.Vary, "*")
+
+	if noStore && freshness > 0 {
+		info.Issues = append(info.Issues, "no-store overrides the freshness lifetime")
+	}
+	if noCache && freshness > 0 {
+		info.Issues = append(info.Issues, "no-cache forces revalidation on every use despite a freshness lifetime")
+	}
+	if isPrivate {
+		if _, ok := info.Directives["s-maxage"]; ok {
+			info.Issues = append(info.Issues, "s-maxage has no effect on private responses")
+		}
+	}
+	if contains(info.Vary, "*") {
+		info.Issues = append(info.Issues, "Vary: * makes the response uncacheable")
+	}
+	for _, field := range info.Vary {
+		if strings.EqualFold(field, "User-Agent") || strings.EqualFold(field, "Cookie") {
+			info.Issues = append(info.Issues, fmt.Sprintf("Vary: %s fragments shared caches", field))
+		}
+	}
+
+	// Conditional revalidation
+	if info.ETag != "" {
+		info.ETagRevalidation = nc.revalidate(targetURL, "If-None-Match", info.ETag)
+		if !info.ETagRevalidation.NotModified && info.ETagRevalidation.Error == "" {
+			info.Issues = append(info.Issues, fmt.Sprintf("ETag revalidation returned %d instead of 304", info.ETagRevalidation.Status))
+		}
+	}
+	if info.LastModified != "" {
+		info.LastModifiedRevalidation = nc.revalidate(targetURL, "If-Modified-Since", info.LastModified)
+		if !info.LastModifiedRevalidation.NotModified && info.LastModifiedRevalidation.Error == "" {
+			info.Issues = append(info.Issues, fmt.Sprintf("Last-Modified revalidation returned %d instead of 304", info.LastModifiedRevalidation.Status))
+		}
+	}
+	if info.ETag == "" && info.LastModified == "" && info.Cacheable {
+		info.Issues = append(info.Issues, "No ETag or Last-Modified validator; caches must refetch the full response when stale")
+	}
+
+	return info
+}
+
+// CheckCompressionCaching analyzes supported content encodings and caching behaviour of a URL
+func (nc *NetChecker) CheckCompressionCaching(target string) CompressionCachingInfo {
+	info := CompressionCachingInfo{URL: target}
+
+	targetURL := target
+	if !strings.HasPrefix(targetURL, "https://") && !strings.HasPrefix(targetURL, "http://") {
+		targetURL = "https://" + targetURL
+	}
+
+	// Resolve redirects once so every probe hits the same final URL
+	req, err := http.NewRequest("GET", targetURL, nil)
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to create request: %v", err)
+		return info
+	}
+	req.Header.Set("Accept-Encoding", "identity")
+	resp, chain, err := nc.followRedirects(req, true)
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to connect: %v", err)
+		return info
+	}
+	identityBody, err := io.ReadAll(io.LimitReader(resp.Body, maxTimedBodyBytes))
+	resp.Body.Close()
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to read response: %v", err)
+		return info
+	}
+	info.FinalURL = chain.FinalURL
+
+	info.Compression.UncompressedBytes = int64(len(identityBody))
+	if ce := resp.Header.Get("Content-Encoding"); ce != "" && ce != "identity" {
+		info.Compression.Issues = append(info.Compression.Issues, fmt.Sprintf("Server sent %s although only identity was accepted", ce))
+	}
+
+	// Offer each encoding on its own
+	info.Compression.Supported = []string{}
+	for _, encoding := range compressionEncodings {
+		result := EncodingResult{Encoding: encoding}
+		encResp, body, err := nc.fetchRaw("GET", info.FinalURL, http.Header{"Accept-Encoding": []string{encoding}}, isPublicIP)
+		if err != nil {
+			result.Error = err.Error()
+			info.Compression.Encodings = append(info.Compression.Encodings, result)
+			continue
+		}
+		result.Status = encResp.StatusCode
+		result.ContentEncoding = encResp.Header.Get("Content-Encoding")
+		result.Bytes = int64(len(body))
+		result.Supported = strings.EqualFold(result.ContentEncoding, encoding)
+		if result.Supported {
+			info.Compression.Supported = append(info.Compression.Supported, encoding)
+			if info.Compression.UncompressedBytes > 0 {
+				result.SavingsPercent = round3(100 - float64(result.Bytes)/float64(info.Compression.UncompressedBytes)*100)
+			}
+			if !headerListContains(encResp.Header.Values("Vary"), "Accept-Encoding") {
+				info.Compression.Issues = append(info.Compression.Issues, fmt.Sprintf("%s response lacks Vary: Accept-Encoding; shared caches may serve it to clients that cannot decode it", encoding))
+			}
+		}
+		info.Compression.Encodings = append(info.Compression.Encodings, result)
+	}
+
+	if len(info.Compression.Supported) == 0 && info.Compression.UncompressedBytes > 1024 {
+		info.Compression.Issues = append(info.Compression.Issues, "No compression supported for a response larger than 1 KB")
+	}
+
+	info.Caching = nc.analyzeCaching(info.FinalURL, resp)
+
+	return info
+}
+
+// headerListContains reports whether a comma separated header contains a token
+func headerListContains(values []string, token string) bool {
+	for _, v := range values {
+		for _, field := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func handleCompressionCaching(c *gin.Context) {
+	target := c.Query("url")
+	if target == "" {
+		target = c.Query("domain")
+	}
+	if target == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "URL or domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/compression-caching", map[string]string{"url": target})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	compressionInfo := checker.CheckCompressionCaching(target)
+	if ttl, ok := routeTTL["/api/v1/compression-caching"]; ok {
+		apiCache.Set(key, compressionInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    compressionInfo,
+	})
+}
+
+// -----------------------------
+// CORS policy probing
+// -----------------------------
+
+// hostileCORSOrigin is an attacker-controlled origin that should never be trusted
+const hostileCORSOrigin = "https://netcheck-evil.example"
+
+// CORSProbe represents the Access-Control-* response to a single Origin
+type CORSProbe struct {
+	Origin           string   `json:"origin"`
+	Kind             string   `json:"kind"` // same-origin, hostile, null, subdomain, suffix, custom
+	Type             string   `json:"type"` // simple, preflight
+	Status           int      `json:"status"`
+	AllowOrigin      string   `json:"allow_origin,omitempty"`
+	AllowCredentials bool     `json:"allow_credentials"`
+	AllowMethods     []string `json:"allow_methods,omitempty"`
+	AllowHeaders     []string `json:"allow_headers,omitempty"`
+	ExposeHeaders    []string `json:"expose_headers,omitempty"`
+	MaxAge           string   `json:"max_age,omitempty"`
+	VaryOrigin       bool     `json:"vary_origin"`
+	Allowed          bool     `json:"allowed"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// CORSFinding represents a CORS misconfiguration
+type CORSFinding struct {
+	Severity string `json:"severity"` // critical, high, medium, low
+	Origin   string `json:"origin,omitempty"`
+	Issue    string `json:"issue"`
+}
+
+// CORSInfo represents the CORS policy of a URL
+type CORSInfo struct {
+	URL            string        `json:"url"`
+	RequestMethod  string        `json:"request_method"`
+	RequestHeaders []string      `json:"request_headers"`
+	Probes         []CORSProbe   `json:"probes"`
+	AllowedOrigins []string      `json:"allowed_origins"`
+	ReflectsOrigin bool          `json:"reflects_origin"`
+	AllowsNull     bool          `json:"allows_null"`
+	WildcardOrigin bool          `json:"wildcard_origin"`
+	AllowedMethods []string      `json:"allowed_methods,omitempty"`
+	AllowedHeaders []string      `json:"allowed_headers,omitempty"`
+	Findings       []CORSFinding `json:"findings"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// splitHeaderList splits a comma separated header into trimmed tokens
+func splitHeaderList(value string) []string {
+	var tokens []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+// defaultCORSOrigins builds the probe origins for a target URL
+func defaultCORSOrigins(u *url.URL) map[string]string {
+	host := u.Hostname()
+	origins := map[string]string{
+		u.Scheme + "://" + u.Host:             "same-origin",
+		hostileCORSOrigin:                     "hostile",
+		"null":                                "null",
+		u.Scheme + "://netcheck-test." + host: "subdomain",
+		"https://" + host + ".netcheck-evil.example":               "suffix",
+		"https://netcheck-evil" + strings.TrimPrefix(host, "www."): "prefix",
+	}
+	return origins
+}
+
+// corsSafeMethod reports whether method cannot change state on the target
+func corsSafeMethod(method string) bool {
+	return method == "GET" || method == "HEAD"
+}
+
+// probeCORS sends a simple or preflight request with the given Origin
+func (nc *NetChecker) probeCORS(targetURL, origin, kind string, preflight bool, method string, headers []string) CORSProbe {
+	probe := CORSProbe{Origin: origin, Kind: kind, Type: "simple"}
+
+	reqHeader := http.Header{"Origin": []string{origin}}
+	reqMethod := "GET"
+	allowed := isPublicIP
+	if preflight {
+		probe.Type = "preflight"
+		reqMethod = "OPTIONS"
+		reqHeader.Set("Access-Control-Request-Method", method)
+		if len(headers) > 0 {
+			reqHeader.Set("Access-Control-Request-Headers", strings.ToLower(strings.Join(headers, ",")))
+		}
+		if !corsSafeMethod(method) {
+			allowed = isRoutableIP
+		}
+	}
+
+	resp, _, err := nc.fetchRaw(reqMethod, targetURL, reqHeader, allowed)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+
+	probe.Status = resp.StatusCode
+	probe.AllowOrigin = resp.Header.Get("Access-Control-Allow-Origin")
+	probe.AllowCredentials = strings.EqualFold(strings.TrimSpace(resp.Header.Get("Access-Control-Allow-Credentials")), "true")
+	probe.AllowMethods = splitHeaderList(resp.Header.Get("Access-Control-Allow-Methods"))
+	probe.AllowHeaders = splitHeaderList(resp.Header.Get("Access-Control-Allow-Headers"))
+	probe.ExposeHeaders = splitHeaderList(resp.Header.Get("Access-Control-Expose-Headers"))
+	probe.MaxAge = resp.Header.Get("Access-Control-Max-Age")
+	probe.VaryOrigin = headerListContains(resp.Header.Values("Vary"), "Origin")
+	probe.Allowed = probe.AllowOrigin == "*" || probe.AllowOrigin == origin
+
+	return probe
+}
+
+// CheckCORS probes the CORS policy of a URL with a set of Origins
+func (nc *NetChecker) CheckCORS(target string, origins []string, method string, headers []string) CORSInfo {
+	info := CORSInfo{
+		URL:            target,
+		RequestMethod:  method,
+		RequestHeaders: headers,
+		Probes:         []CORSProbe{},
+		AllowedOrigins: []string{},
+		Findings:       []CORSFinding{},
+	}
+
+	targetURL := target
+	if !strings.HasPrefix(targetURL, "https://") && !strings.HasPrefix(targetURL, "http://") {
+		targetURL = "https://" + targetURL
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil || u.Host == "" {
+		info.Error = fmt.Sprintf("Invalid URL: %s", target)
+		return info
+	}
+	ip, err := resolveTarget(u.Hostname())
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	if !corsSafeMethod(method) && !isRoutableIP(ip) {
+		info.Error = fmt.Sprintf("Preflights for %s may only be sent to public addresses", method)
+		return info
+	}
+	info.URL = targetURL
+
+	probeOrigins := defaultCORSOrigins(u)
+	for _, origin := range origins {
+		if _, ok := probeOrigins[origin]; !ok {
+			probeOrigins[origin] = "custom"
+		}
+	}
+	ordered := make([]string, 0, len(probeOrigins))
+	for origin := range probeOrigins {
+		ordered = append(ordered, origin)
+	}
+	sort.Strings(ordered)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 4)
+	for _, origin := range ordered {
+		for _, preflight := range []bool{false, true} {
+			wg.Add(1)
+			go func(origin string, preflight bool) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				probe := nc.probeCORS(targetURL, origin, probeOrigins[origin], preflight, method, headers)
+				mu.Lock()
+				info.Probes = append(info.Probes, probe)
+				mu.Unlock()
+			}(origin, preflight)
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(info.Probes, func(i, j int) bool {
+		if info.Probes[i].Origin != info.Probes[j].Origin {
+			return info.Probes[i].Origin < info.Probes[j].Origin
+		}
+		return info.Probes[i].Type > info.Probes[j].Type
+	})
+
+	addFinding := func(severity, origin, issue string) {
+		for _, f := range info.Findings {
+			if f.Origin == origin && f.Issue == issue {
+				return
+			}
+		}
+		info.Findings = append(info.Findings, CORSFinding{Severity: severity, Origin: origin, Issue: issue})
+	}
+
+	methods := make(map[string]bool)
+	allowHeaders := make(map[string]bool)
+	for _, probe := range info.Probes {
+		if probe.Error != "" {
+			continue
+		}
+		if probe.AllowOrigin == "*" {
+			info.WildcardOrigin = true
+			if probe.AllowCredentials {
+				addFinding("medium", "", "Access-Control-Allow-Origin: * combined with Access-Control-Allow-Credentials: true (browsers reject it, but it signals an intent to share credentialed responses)")
+			}
+		}
+		if probe.Type == "preflight" && probe.Allowed {
+			for _, m := range probe.AllowMethods {
+				methods[strings.ToUpper(m)] = true
+			}
+			for _, h := range probe.AllowHeaders {
+				allowHeaders[strings.ToLower(h)] = true
+			}
+		}
+		if !probe.Allowed || probe.AllowOrigin == "*" {
+			continue
+		}
+		if !contains(info.AllowedOrigins, probe.Origin) {
+			info.AllowedOrigins = append(info.AllowedOrigins, probe.Origin)
+		}
+		if !probe.VaryOrigin && probe.Type == "simple" {
+			addFinding("low", "", "Origin-specific Access-Control-Allow-Origin without Vary: Origin allows cache poisoning")
+		}
+
+		withCreds := ""
+		severity := "high"
+		if probe.AllowCredentials {
+			withCreds = " with credentials"
+			severity = "critical"
+		}
+		switch probe.Kind {
+		case "hostile", "suffix", "prefix":
+			info.ReflectsOrigin = true
+			addFinding(severity, probe.Origin, fmt.Sprintf("Arbitrary origin reflected in Access-Control-Allow-Origin%s", withCreds))
+		case "null":
+			info.AllowsNull = true
+			addFinding(severity, probe.Origin, fmt.Sprintf("null origin trusted%s; sandboxed iframes and local files can read responses", withCreds))
+		case "subdomain":
+			if !probe.AllowCredentials {
+				severity = "low"
+			} else {
+				severity = "medium"
+			}
+			addFinding(severity, probe.Origin, fmt.Sprintf("Any subdomain trusted%s; a single subdomain XSS or takeover exposes this resource", withCreds))
+		}
+	}
+
+	for m := range methods {
+		info.AllowedMethods = append(info.AllowedMethods, m)
+	}
+	sort.Strings(info.AllowedMethods)
+	for h := range allowHeaders {
+		info.AllowedHeaders = append(info.AllowedHeaders, h)
+	}
+	sort.Strings(info.AllowedHeaders)
+	if allowHeaders["*"] {
+		addFinding("low", "", "Access-Control-Allow-Headers: * allows any request header")
+	}
+	sort.Strings(info.AllowedOrigins)
+
+	return info
+}
+
+func handleCORS(c *gin.Context) {
+	target := c.Query("url")
+	if target == "" {
+		target = c.Query("domain")
+	}
+	if target == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "URL or domain parameter is required",
+		})
+		return
+	}
+
+	origins := splitHeaderList(c.Query("origins"))
+	if len(origins) > 10 {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "At most 10 origins may be specified",
+		})
+		return
+	}
+	method := strings.ToUpper(c.DefaultQuery("method", "PUT"))
+	headers := splitHeaderList(c.DefaultQuery("headers", "Authorization,X-Requested-With"))
+
+	key := cacheKey("/api/v1/cors", map[string]string{
+		"url":     target,
+		"origins": strings.Join(origins, ","),
+		"method":  method,
+		"headers": strings.Join(headers, ","),
+	})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	corsInfo := checker.CheckCORS(target, origins, method, headers)
+	if ttl, ok := routeTTL["/api/v1/cors"]; ok {
+		apiCache.Set(key, corsInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    corsInfo,
+	})
+}
+
+// -----------------------------
+// HTTP method exposure
+// -----------------------------
+
+// probedMethods are sent without a body; state-changing verbs target a random
+// sibling resource so that nothing existing can be modified
+var probedMethods = []string{"OPTIONS", "TRACE", "PROPFIND", "PUT", "DELETE", "PATCH", "CONNECT"}
+
+// unsafeMethods are the probed verbs that could change server state
+var unsafeMethods = []string{"PUT", "DELETE", "PATCH"}
+
+// webDAVMethods indicate a WebDAV handler when advertised
+var webDAVMethods = []string{"PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK"}
+
+// MethodProbe represents the response to a single method
+type MethodProbe struct {
+	Method  string `json:"method"`
+	URL     string `json:"url"`
+	Status  int    `json:"status"`
+	Outcome string `json:"outcome"` // accepted, redirected, not_allowed, unauthorized, not_found, other, error
+	Allow   string `json:"allow,omitempty"`
+	DAV     string `json:"dav,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// MethodFinding represents a dangerous method exposure
+type MethodFinding struct {
+	Severity string `json:"severity"` // high, medium, low, info
+	Method   string `json:"method,omitempty"`
+	Issue    string `json:"issue"`
+}
+
+// HTTPMethodsInfo represents advertised versus actual HTTP method support
+type HTTPMethodsInfo struct {
+	URL                  string          `json:"url"`
+	ProbeURL             string          `json:"probe_url"`
+	Advertised           []string        `json:"advertised"`
+	Accepted             []string        `json:"accepted"`
+	Probes               []MethodProbe   `json:"probes"`
+	TraceReflected       bool            `json:"trace_reflected"`
+	WebDAV               bool            `json:"webdav"`
+	DAVHeader            string          `json:"dav_header,omitempty"`
+	AdvertisedOnly       []string        `json:"advertised_only,omitempty"`
+	UnadvertisedAccepted []string        `json:"unadvertised_accepted,omitempty"`
+	Findings             []MethodFinding `json:"findings"`
+	Error                string          `json:"error,omitempty"`
+}
+
+// methodOutcome classifies a status code
+func methodOutcome(status int) string {
+	switch {
+	case status >= 200 && status < 300:
+		return "accepted"
+	case status >= 300 && status < 400:
+		return "redirected"
+	case status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented:
+		return "not_allowed"
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return "unauthorized"
+	case status == http.StatusNotFound:
+		return "not_found"
+	}
+	return "other"
+}
+
+// randomToken returns a random hex string of n bytes
+func randomToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// probeMethod sends one bodyless request and returns the outcome and up to 64 KB of body
+func (nc *NetChecker) probeMethod(method, targetURL string, header http.Header) (MethodProbe, []byte) {
+	probe := MethodProbe{Method: method, URL: targetURL}
+
+	req, err := http.NewRequest(method, targetURL, nil)
+	if err != nil {
+		probe.Outcome = "error"
+		probe.Error = err.Error()
+		return probe, nil
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", "NetCheck-API/1.0")
+
+	// The probes include state-changing verbs, so every connection must reach a
+	// routable address, whatever ALLOW_PRIVATE_TARGETS says
+	client := nc.guardedHTTPClient(isRoutableIP)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		probe.Outcome = "error"
+		probe.Error = err.Error()
+		return probe, nil
+	}
+	defer resp.Body.Close()
+
+	probe.Status = resp.StatusCode
+	probe.Outcome = methodOutcome(resp.StatusCode)
+	probe.Allow = resp.Header.Get("Allow")
+	probe.DAV = resp.Header.Get("DAV")
+
+	// A successful CONNECT turns the connection into a tunnel; never read from it
+	if method == "CONNECT" {
+		return probe, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	return probe, body
+}
+
+// CheckHTTPMethods probes which HTTP methods a URL accepts and flags dangerous ones
+func (nc *NetChecker) CheckHTTPMethods(target string) HTTPMethodsInfo {
+	info := HTTPMethodsInfo{
+		URL:        target,
+		Advertised: []string{},
+		Accepted:   []string{},
+		Probes:     []MethodProbe{},
+		Findings:   []MethodFinding{},
+	}
+
+	targetURL := target
+	if !strings.HasPrefix(targetURL, "https://") && !strings.HasPrefix(targetURL, "http://") {
+		targetURL = "https://" + targetURL
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil || u.Host == "" {
+		info.Error = fmt.Sprintf("Invalid URL: %s", target)
+		return info
+	}
+	ip, err := resolveTarget(u.Hostname())
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	if !isRoutableIP(ip) {
+		info.Error = "PUT, DELETE, PATCH and CONNECT probes may only be sent to public addresses"
+		return info
+	}
+	info.URL = u.String()
+
+	// Unsafe verbs go to a resource that cannot exist
+	probeURL := *u
+	dir := u.Path
+	if !strings.HasSuffix(dir, "/") {
+		dir = dir[:strings.LastIndex(dir, "/")+1]
+	}
+	if dir == "" {
+		dir = "/"
+	}
+	probeURL.Path = dir + "netcheck-probe-" + randomToken(8)
+	probeURL.RawQuery = ""
+	info.ProbeURL = probeURL.String()
+
+	traceToken := randomToken(12)
+	advertised := make(map[string]bool)
+	addFinding := func(severity, method, issue string) {
+		info.Findings = append(info.Findings, MethodFinding{Severity: severity, Method: method, Issue: issue})
+	}
+
+	for _, method := range probedMethods {
+		header := http.Header{}
+		target := info.URL
+		switch method {
+		case "TRACE":
+			header.Set("X-NetCheck-Trace", traceToken)
+		case "PROPFIND":
+			header.Set("Depth", "0")
+		case "PUT", "DELETE", "PATCH":
+			target = info.ProbeURL
+			header.Set("Content-Length", "0")
+		}
+
+		probe, body := nc.probeMethod(method, target, header)
+		if probe.DAV != "" {
+			info.DAVHeader = probe.DAV
+		}
+		for _, m := range splitHeaderList(probe.Allow) {
+			advertised[strings.ToUpper(m)] = true
+		}
+		if probe.Outcome == "accepted" {
+			info.Accepted = append(info.Accepted, method)
+		}
+
+		switch method {
+		case "TRACE":
+			if probe.Outcome == "accepted" {
+				if strings.Contains(string(body), traceToken) {
+					info.TraceReflected = true
+					addFinding("high", method, "TRACE echoes request headers (cross-site tracing); disable TRACE")
+				} else {
+					addFinding("medium", method, "TRACE is enabled; disable it")
+				}
+			}
+		case "PROPFIND":
+			if probe.Status == http.StatusMultiStatus {
+				info.WebDAV = true
+			}
+		case "PUT", "DELETE", "PATCH":
+			if probe.Outcome == "accepted" {
+				addFinding("high", method, fmt.Sprintf("%s on a nonexistent resource returned %d; the server may allow unauthenticated modification", method, probe.Status))
+			}
+		case "CONNECT":
+			if probe.Outcome == "accepted" {
+				addFinding("high", method, "CONNECT succeeded; the server may act as an open proxy")
+			}
+		}
+
+		info.Probes = append(info.Probes, probe)
+	}
+
+	for m := range advertised {
+		info.Advertised = append(info.Advertised, m)
+	}
+	sort.Strings(info.Advertised)
+
+	// WebDAV exposure
+	if info.DAVHeader != "" {
+		info.WebDAV = true
+	}
+	for _, m := range webDAVMethods {
+		if advertised[m] {
+			info.WebDAV = true
+		}
+	}
+	if info.WebDAV {
+		addFinding("medium", "", "WebDAV is exposed; disable it unless required and restrict it to authenticated users")
+	}
+
+	// Compare advertised and actual behaviour
+	if len(advertised) > 0 {
+		for _, probe := range info.Probes {
+			if probe.Outcome == "accepted" && !advertised[probe.Method] {
+				info.UnadvertisedAccepted = append(info.UnadvertisedAccepted, probe.Method)
+			}
+			if probe.Outcome == "not_allowed" && advertised[probe.Method] {
+				info.AdvertisedOnly = append(info.AdvertisedOnly, probe.Method)
+			}
+		}
+		if len(info.UnadvertisedAccepted) > 0 {
+			addFinding("low", "", fmt.Sprintf("Methods accepted but not listed in Allow: %s", strings.Join(info.UnadvertisedAccepted, ", ")))
+		}
+		if len(info.AdvertisedOnly) > 0 {
+			addFinding("info", "", fmt.Sprintf("Methods listed in Allow but rejected: %s", strings.Join(info.AdvertisedOnly, ", ")))
+		}
+		for _, m := range unsafeMethods {
+			if advertised[m] {
+				addFinding("info", m, fmt.Sprintf("%s is advertised in Allow", m))
+			}
+		}
+		if advertised["TRACE"] && !contains(info.Accepted, "TRACE") {
+			addFinding("low", "TRACE", "TRACE is advertised in Allow")
+		}
+	}
+
+	return info
+}
+
+func handleHTTPMethods(c *gin.Context) {
+	target := c.Query("url")
+	if target == "" {
+		target = c.Query("domain")
+	}
+	if target == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "URL or domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/http-methods", map[string]string{"url": target})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	methodsInfo := checker.CheckHTTPMethods(target)
+	if ttl, ok := routeTTL["/api/v1/http-methods"]; ok {
+		apiCache.Set(key, methodsInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    methodsInfo,
+	})
+}
+
+// -----------------------------
+// Web technology fingerprinting
+// -----------------------------
+
+// defaultFingerprintRules is the bundled rule set, overridable with FINGERPRINT_RULES
+//
+//go:embed fingerprints.json
+var defaultFingerprintRules []byte
+
+// Technology represents a detected web technology
+type Technology struct {
+	Name       string   `json:"name"`
+	Categories []string `json:"categories"`
+	Version    string   `json:"version,omitempty"`
+	Confidence int      `json:"confidence"`
+	ImpliedBy  []string `json:"implied_by,omitempty"`
+	Evidence   []string `json:"evidence,omitempty"`
+}
+
+// fingerprintRule is a single technology in the Wappalyzer-style rules file.
+// Patterns may carry \;version:\1 and \;confidence:N suffixes.
+type fingerprintRule struct {
+	Cats      []string          `json:"cats"`
+	Headers   map[string]string `json:"headers"`
+	Cookies   map[string]string `json:"cookies"`
+	Meta      map[string]string `json:"meta"`
+	ScriptSrc []string          `json:"scriptSrc"`
+	HTML      []string          `json:"html"`
+	Implies   []string          `json:"implies"`
+}
+
+// fingerprintPattern is a compiled rule pattern
+type fingerprintPattern struct {
+	re         *regexp.Regexp
+	version    string
+	confidence int
+}
+
+// compiledFingerprint is a rule with compiled patterns
+type compiledFingerprint struct {
+	name      string
+	cats      []string
+	headers   map[string]fingerprintPattern
+	cookies   map[string]fingerprintPattern
+	meta      map[string]fingerprintPattern
+	scriptSrc []fingerprintPattern
+	html      []fingerprintPattern
+	implies   []string
+}
+
+var (
+	fingerprintsOnce sync.Once
+	fingerprints     []compiledFingerprint
+	fingerprintsErr  error
+
+	metaTagRegex   = regexp.MustCompile(`(?i)<meta\s[^>]*>`)
+	metaNameRegex  = regexp.MustCompile(`(?i)\s(?:name|property)\s*=\s*["']([^"']+)["']`)
+	metaValueRegex = regexp.MustCompile(`(?i)\scontent\s*=\s*["']([^"']*)["']`)
+	scriptSrcRegex = regexp.MustCompile(`(?i)<script[^>]+src\s*=\s*["']([^"']+)["']`)
+)
+
+// maxFingerprintHTML caps how much HTML is matched against html patterns
+const maxFingerprintHTML = 2 << 20
+
+// compileFingerprintPattern parses "regex\;version:\1\;confidence:50"
+func compileFingerprintPattern(raw string) (fingerprintPattern, error) {
+	parts := strings.Split(raw, `\;`)
+	p := fingerprintPattern{confidence: 100}
+	re, err := regexp.Compile("(?i)" + parts[0])
+	if err != nil {
+		return p, err
+	}
+	p.re = re
+	for _, attr := range parts[1:] {
+		kv := strings.SplitN(attr, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "version":
+			p.version = kv[1]
+		case "confidence":
+			if n, err := strconv.Atoi(kv[1]); err == nil {
+				p.confidence = n
+			}
+		}
+	}
+	return p, nil
+}
+
+// loadFingerprints compiles the rules file once
+func loadFingerprints() ([]compiledFingerprint, error) {
+	fingerprintsOnce.Do(func() {
+		data := defaultFingerprintRules
+		if path := os.Getenv("FINGERPRINT_RULES"); path != "" {
+			if data, fingerprintsErr = os.ReadFile(path); fingerprintsErr != nil {
+				return
+			}
+		}
+
+		var rules map[string]fingerprintRule
+		if fingerprintsErr = json.Unmarshal(data, &rules); fingerprintsErr != nil {
+			return
+		}
+
+		compileMap := func(name string, m map[string]string) map[string]fingerprintPattern {
+			out := make(map[string]fingerprintPattern)
+			for key, raw := range m {
+				p, err := compileFingerprintPattern(raw)
+				if err != nil {
+					fmt.Printf("Warning: fingerprint %s has invalid pattern %q: %v\n", name, raw, err)
+					continue
+				}
+				out[strings.ToLower(key)] = p
+			}
+			return out
+		}
+		compileList := func(name string, list []string) []fingerprintPattern {
+			var out []fingerprintPattern
+			for _, raw := range list {
+				p, err := compileFingerprintPattern(raw)
+				if err != nil {
+					fmt.Printf("Warning: fingerprint %s has invalid pattern %q: %v\n", name, raw, err)
+					continue
+				}
+				out = append(out, p)
+			}
+			return out
+		}
+
+		for name, rule := range rules {
+			fingerprints = append(fingerprints, compiledFingerprint{
+				name:      name,
+				cats:      rule.Cats,
+				headers:   compileMap(name, rule.Headers),
+				cookies:   compileMap(name, rule.Cookies),
+				meta:      compileMap(name, rule.Meta),
+				scriptSrc: compileList(name, rule.ScriptSrc),
+				html:      compileList(name, rule.HTML),
+				implies:   rule.Implies,
+			})
+		}
+		sort.Slice(fingerprints, func(i, j int) bool { return fingerprints[i].name < fingerprints[j].name })
+	})
+	return fingerprints, fingerprintsErr
+}
+
+// resolveVersion expands \1..\9 in a version template from regex submatches
+func resolveVersion(template string, match []string) string {
+	if template == "" {
+		return ""
+	}
+	version := template
+	for i := len(match) - 1; i >= 1; i-- {
+		version = strings.ReplaceAll(version, `\`+strconv.Itoa(i), match[i])
+	}
+	return strings.TrimSpace(version)
+}
+
+// DetectTechnologies matches response headers, cookies and HTML against the fingerprint
+// rules. cookies adds name/value pairs seen elsewhere, e.g. on redirect hops.
+func DetectTechnologies(header http.Header, cookies map[string]string, html string) []Technology {
+	rules, err := loadFingerprints()
+	if err != nil {
+		fmt.Printf("Warning: failed to load fingerprint rules: %v\n", err)
+		return nil
+	}
+
+	if len(html) > maxFingerprintHTML {
+		html = html[:maxFingerprintHTML]
+	}
+
+	// Collect cookies, meta tags and script sources once
+	cookieJar := make(map[string]string)
+	for name, value := range cookies {
+		cookieJar[strings.ToLower(name)] = value
+	}
+	for _, raw := range header.Values("Set-Cookie") {
+		if cookie, err := http.ParseSetCookie(raw); err == nil {
+			cookieJar[strings.ToLower(cookie.Name)] = cookie.Value
+		} else {
+			cookieJar[strings.ToLower(strings.TrimSpace(strings.SplitN(raw, "=", 2)[0]))] = ""
+		}
+	}
+	metaTags := make(map[string][]string)
+	for _, tag := range metaTagRegex.FindAllString(html, -1) {
+		name := metaNameRegex.FindStringSubmatch(tag)
+		content := metaValueRegex.FindStringSubmatch(tag)
+		if name != nil && content != nil {
+			key := strings.ToLower(name[1])
+			metaTags[key] = append(metaTags[key], content[1])
+		}
+	}
+	var scripts []string
+	for _, m := range scriptSrcRegex.FindAllStringSubmatch(html, -1) {
+		scripts = append(scripts, m[1])
+	}
+
+	detected := make(map[string]*Technology)
+	record := func(rule compiledFingerprint, p fingerprintPattern, match []string, evidence string) {
+		tech, ok := detected[rule.name]
+		if !ok {
+			tech = &Technology{Name: rule.name, Categories: rule.cats}
+			detected[rule.name] = tech
+		}
+		tech.Confidence += p.confidence
+		if tech.Confidence > 100 {
+			tech.Confidence = 100
+		}
+		if v := resolveVersion(p.version, match); v != "" && len(v) > len(tech.Version) {
+			tech.Version = v
+		}
+		tech.Evidence = append(tech.Evidence, evidence)
+	}
+
+	for _, rule := range rules {
+		for name, p := range rule.headers {
+			for _, value := range header.Values(name) {
+				if match := p.re.FindStringSubmatch(value); match != nil {
+					record(rule, p, match, "header "+http.CanonicalHeaderKey(name))
+					break
+				}
+			}
+		}
+		// Cookie names match exactly or, with a trailing *, by prefix; the pattern
+		// is matched against the value
+		for name, p := range rule.cookies {
+			for cookie, value := range cookieJar {
+				if cookie != name && !(strings.HasSuffix(name, "*") && strings.HasPrefix(cookie, strings.TrimSuffix(name, "*"))) {
+					continue
+				}
+				if match := p.re.FindStringSubmatch(value); match != nil {
+					record(rule, p, match, "cookie "+cookie)
+					break
+				}
+			}
+		}
+		for name, p := range rule.meta {
+			for _, content := range metaTags[name] {
+				if match := p.re.FindStringSubmatch(content); match != nil {
+					record(rule, p, match, "meta "+name)
+					break
+				}
+			}
+		}
+		for _, p := range rule.scriptSrc {
+			for _, src := range scripts {
+				if match := p.re.FindStringSubmatch(src); match != nil {
+					record(rule, p, match, "script "+src)
+					break
+				}
+			}
+		}
+		for _, p := range rule.html {
+			if match := p.re.FindStringSubmatch(html); match != nil {
+				record(rule, p, match, "html")
+			}
+		}
+	}
+
+	// Add implied technologies transitively
+	byName := make(map[string]compiledFingerprint)
+	for _, rule := range rules {
+		byName[rule.name] = rule
+	}
+	queue := make([]string, 0, len(detected))
+	for name := range detected {
+		queue = append(queue, name)
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, implied := range byName[name].implies {
+			tech, ok := detected[implied]
+			if !ok {
+				tech = &Technology{Name: implied, Categories: byName[implied].cats, Confidence: detected[name].Confidence}
+				detected[implied] = tech
+				queue = append(queue, implied)
+			}
+			if !contains(tech.ImpliedBy, name) {
+				tech.ImpliedBy = append(tech.ImpliedBy, name)
+			}
+		}
+	}
+
+	technologies := make([]Technology, 0, len(detected))
+	for _, tech := range detected {
+		technologies = append(technologies, *tech)
+	}
+	sort.Slice(technologies, func(i, j int) bool {
+		if technologies[i].Confidence != technologies[j].Confidence {
+			return technologies[i].Confidence > technologies[j].Confidence
+		}
+		return technologies[i].Name < technologies[j].Name
+	})
+	return technologies
+}
+
+func handleTechnologies(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/technologies", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+
+	checker := NewNetChecker()
+
+	// Get web settings which includes the fingerprinting result
+	webInfo := checker.CheckWebSettings(domain, defaultWebRequest)
+	if webInfo.Error != "" {
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"domain": domain,
+				"error":  webInfo.Error,
+			},
+		})
+		return
+	}
+
+	data := map[string]interface{}{
+		"domain":       domain,
+		"final_url":    webInfo.Redirects.FinalURL,
+		"technologies": webInfo.Technologies,
+	}
+	if ttl, ok := routeTTL["/api/v1/technologies"]; ok {
+		apiCache.Set(key, data, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// CheckRobotsTxt checks robots.txt file for a domain
+func (nc *NetChecker) CheckRobotsTxt(domain string) RobotsTxtInfo {
+	info := RobotsTxtInfo{Domain: domain}
+
+	// Clean domain
+	cleanDomain := strings.TrimPrefix(domain, "https://")
+	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
+	cleanDomain = strings.Split(cleanDomain, "/")[0]
+
+	// Build robots.txt URL
+	robotsURL := fmt.Sprintf("https://%s/robots.txt", cleanDomain)
+
+	// Try HTTP if HTTPS fails
+	resp, err := nc.httpClient.Get(robotsURL)
+	if err != nil {
+		robotsURL = fmt.Sprintf("http://%s/robots.txt", cleanDomain)
+		resp, err = nc.httpClient.Get(robotsURL)
+		if err != nil {
+			info.Exists = false
+			info.Status = "Not Found"
+			info.Error = fmt.Sprintf("Failed to fetch robots.txt: %v", err)
+			return info
+		}
+	}
+	defer resp.Body.Close()
+
+	info.Exists = true
+	info.Status = fmt.Sprintf("HTTP %d", resp.StatusCode)
+
+	// Read content
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to read response: %v", err)
+		return info
+	}
+
+	info.Content = string(body)
+	info.Lines = strings.Split(info.Content, "\n")
+
+	// Parse robots.txt content
+	currentUserAgent := "*"
+	for _, line := range info.Lines {
+		line = strings.TrimSpace(line)
+
+		// Skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		field := strings.TrimSpace(strings.ToLower(parts[0]))
+		value := strings.TrimSpace(parts[1])
+
+		switch field {
+		case "user-agent":
+			currentUserAgent = value
+			info.UserAgents = append(info.UserAgents, value)
+		case "disallow":
+			if value != "" {
+				info.Disallowed = append(info.Disallowed, fmt.Sprintf("%s: %s", currentUserAgent, value))
+			}
+		case "allow":
+			if value != "" {
+				info.Allowed = append(info.Allowed, fmt.Sprintf("%s: %s", currentUserAgent, value))
+			}
+		case "sitemap":
+			info.Sitemaps = append(info.Sitemaps, value)
+		case "crawl-delay":
+			if value != "" {
+				info.CrawlDelay = value
+			}
+		}
+	}
+
+	return info
+}
+
+func handleRobotsTxt(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/robots-txt", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	robotsInfo := checker.CheckRobotsTxt(domain)
+	if ttl, ok := routeTTL["/api/v1/robots-txt"]; ok {
+		apiCache.Set(key, robotsInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    robotsInfo,
+	})
+}
+
+// -----------------------------
+// Well-known endpoints audit
+// -----------------------------
+
+// maxWellKnownBytes caps how much of a well-known resource is read
+const maxWellKnownBytes = 1 << 20
+
+// SecurityTxtInfo represents a security.txt file validated against RFC 9116
+type SecurityTxtInfo struct {
+	Exists             bool                `json:"exists"`
+	URL                string              `json:"url,omitempty"`
+	StatusCode         int                 `json:"status_code,omitempty"`
+	ContentType        string              `json:"content_type,omitempty"`
+	Fields             map[string][]string `json:"fields,omitempty"`
+	Contact            []string            `json:"contact,omitempty"`
+	Expires            string              `json:"expires,omitempty"`
+	Expired            bool                `json:"expired"`
+	DaysUntilExpiry    *int                `json:"days_until_expiry,omitempty"`
+	PGPWrapped         bool                `json:"pgp_wrapped"` // OpenPGP cleartext signature present; the signature is not verified
+	Canonical          []string            `json:"canonical,omitempty"`
+	Encryption         []string            `json:"encryption,omitempty"`
+	Policy             []string            `json:"policy,omitempty"`
+	PreferredLanguages string              `json:"preferred_languages,omitempty"`
+	Valid              bool                `json:"valid"`
+	Issues             []string            `json:"issues,omitempty"`
+	Error              string              `json:"error,omitempty"`
+}
+
+// WellKnownResource represents a single /.well-known resource
+type WellKnownResource struct {
+	Name        string                 `json:"name"`
+	URL         string                 `json:"url"`
+	Exists      bool                   `json:"exists"`
+	StatusCode  int                    `json:"status_code,omitempty"`
+	ContentType string                 `json:"content_type,omitempty"`
+	Size        int                    `json:"size,omitempty"`
+	FinalURL    string                 `json:"final_url,omitempty"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+	ParseError  string                 `json:"parse_error,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+// WellKnownInfo represents the /.well-known audit of a domain
+type WellKnownInfo struct {
+	Domain      string              `json:"domain"`
+	SecurityTxt SecurityTxtInfo     `json:"security_txt"`
+	Resources   []WellKnownResource `json:"resources"`
+}
+
+// wellKnownResult is a fetched well-known resource
+type wellKnownResult struct {
+	url         string
+	finalURL    string
+	status      int
+	contentType string
+	body        []byte
+}
+
+// fetchWellKnown fetches path from the domain over HTTPS, falling back to HTTP
+// unless httpsOnly is set
+func (nc *NetChecker) fetchWellKnown(host, path string, httpsOnly bool) (wellKnownResult, error) {
+	result := wellKnownResult{url: fmt.Sprintf("https://%s%s", host, path)}
+
+	resp, err := nc.httpClient.Get(result.url)
+	if err != nil && !httpsOnly {
+		result.url = fmt.Sprintf("http://%s%s", host, path)
+		resp, err = nc.httpClient.Get(result.url)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	result.finalURL = resp.Request.URL.String()
+	result.status = resp.StatusCode
+	result.contentType = resp.Header.Get("Content-Type")
+	result.body, err = io.ReadAll(io.LimitReader(resp.Body, maxWellKnownBytes))
+	return result, err
+}
+
+// mediaType returns the lower-cased media type of a Content-Type header
+func mediaType(contentType string) string {
+	return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+}
+
+// parseSecurityTxt validates security.txt content per RFC 9116
+func parseSecurityTxt(info *SecurityTxtInfo, content string) {
+	info.Fields = make(map[string][]string)
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	// Strip an OpenPGP cleartext signature wrapper. Verifying the signature would need
+	// the publisher's key, so only the wrapper's presence is reported.
+	if strings.HasPrefix(strings.TrimSpace(content), "-----BEGIN PGP SIGNED MESSAGE-----") {
+		info.PGPWrapped = true
+		if i := strings.Index(content, "\n\n"); i >= 0 {
+			content = content[i+2:]
+		}
+		if i := strings.Index(content, "-----BEGIN PGP SIGNATURE-----"); i >= 0 {
+			content = content[:i]
+		} else {
+			info.Issues = append(info.Issues, "PGP signed message has no signature block")
+		}
+	}
+
+	for n, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "- "))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.ContainsAny(parts[0], " \t") {
+			info.Issues = append(info.Issues, fmt.Sprintf("Line %d is not a valid field: %q", n+1, line))
+			continue
+		}
+		field := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+		info.Fields[field] = append(info.Fields[field], value)
+	}
+
+	info.Contact = info.Fields["contact"]
+	info.Canonical = info.Fields["canonical"]
+	info.Encryption = info.Fields["encryption"]
+	info.Policy = info.Fields["policy"]
+
+	if len(info.Contact) == 0 {
+		info.Issues = append(info.Issues, "Required Contact field is missing")
+	}
+	for _, contact := range info.Contact {
+		u, err := url.Parse(contact)
+		if err != nil || u.Scheme == "" {
+			info.Issues = append(info.Issues, fmt.Sprintf("Contact %q is not a URI", contact))
+		} else if u.Scheme == "http" {
+			info.Issues = append(info.Issues, fmt.Sprintf("Contact %q must use HTTPS", contact))
+		}
+	}
+
+	switch expires := info.Fields["expires"]; len(expires) {
+	case 0:
+		info.Issues = append(info.Issues, "Required Expires field is missing")
+	case 1:
+		info.Expires = expires[0]
+		t, err := time.Parse(time.RFC3339, expires[0])
+		if err != nil {
+			info.Issues = append(info.Issues, fmt.Sprintf("Expires %q is not an RFC 3339 timestamp", expires[0]))
+			break
+		}
+		days := int(time.Until(t).Hours() / 24)
+		info.DaysUntilExpiry = &days
+		if time.Now().After(t) {
+			info.Expired = true
+			info.Issues = append(info.Issues, "security.txt has expired")
+		} else if time.Until(t) > 366*24*time.Hour {
+			info.Issues = append(info.Issues, "Expires is more than a year in the future")
+		}
+	default:
+		info.Issues = append(info.Issues, "Expires must appear only once")
+	}
+
+	if len(info.Fields["preferred-languages"]) > 1 {
+		info.Issues = append(info.Issues, "Preferred-Languages must appear only once")
+	}
+	if langs := info.Fields["preferred-languages"]; len(langs) > 0 {
+		info.PreferredLanguages = langs[0]
+	}
+	if !info.PGPWrapped {
+		info.Issues = append(info.Issues, "security.txt is not digitally signed (recommended)")
+	}
+}
+
+// CheckSecurityTxt fetches and validates security.txt per RFC 9116
+func (nc *NetChecker) CheckSecurityTxt(host string) SecurityTxtInfo {
+	info := SecurityTxtInfo{}
+
+	res, err := nc.fetchWellKnown(host, "/.well-known/security.txt", false)
+	if err != nil || res.status != http.StatusOK {
+		// Legacy location at the web root
+		if legacy, lerr := nc.fetchWellKnown(host, "/security.txt", false); lerr == nil && legacy.status == http.StatusOK {
+			res, err = legacy, nil
+			info.Issues = append(info.Issues, "security.txt is only served from the legacy /security.txt location")
+		}
+	}
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to fetch security.txt: %v", err)
+		return info
+	}
+
+	info.URL = res.url
+	info.StatusCode = res.status
+	info.ContentType = res.contentType
+	if res.status != http.StatusOK {
+		return info
+	}
+	info.Exists = true
+
+	if mediaType(res.contentType) != "text/plain" {
+		info.Issues = append(info.Issues, fmt.Sprintf("Content-Type should be text/plain, got %q", res.contentType))
+	}
+	if !strings.HasPrefix(res.finalURL, "https://") {
+		info.Issues = append(info.Issues, "security.txt must be served over HTTPS")
+	}
+
+	parseSecurityTxt(&info, string(res.body))
+
+	if len(info.Canonical) > 0 && !contains(info.Canonical, res.finalURL) {
+		info.Issues = append(info.Issues, fmt.Sprintf("Canonical does not list the URL it was fetched from (%s)", res.finalURL))
+	}
+	info.Valid = len(info.Contact) > 0 && info.Expires != "" && !info.Expired && len(info.Fields["expires"]) == 1
+
+	return info
+}
+
+// parseMTASTSPolicy parses an MTA-STS policy file (RFC 8461 section 3.2)
+func parseMTASTSPolicy(content string) (map[string]interface{}, error) {
+	policy := map[string]interface{}{}
+	var mx []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return policy, fmt.Errorf("invalid line %q", line)
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if key == "mx" {
+			mx = append(mx, value)
+			continue
+		}
+		policy[key] = value
+	}
+	policy["mx"] = mx
+
+	if policy["version"] != "STSv1" {
+		return policy, fmt.Errorf("version must be STSv1")
+	}
+	switch policy["mode"] {
+	case "enforce", "testing", "none":
+	default:
+		return policy, fmt.Errorf("mode must be enforce, testing or none")
+	}
+	maxAge, ok := policy["max_age"].(string)
+	if n, err := strconv.Atoi(maxAge); !ok || err != nil || n < 0 || n > 31557600 {
+		return policy, fmt.Errorf("max_age must be an integer between 0 and 31557600")
+	}
+	if len(mx) == 0 && policy["mode"] != "none" {
+		return policy, fmt.Errorf("at least one mx pattern is required")
+	}
+	return policy, nil
+}
+
+// parseJSONResource decodes a JSON well-known resource and summarizes it
+func parseJSONResource(name string, body []byte) (map[string]interface{}, error) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	details := map[string]interface{}{}
+
+	switch name {
+	case "assetlinks.json":
+		statements, ok := doc.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a JSON array of statements")
+		}
+		var packages []string
+		for _, s := range statements {
+			stmt, _ := s.(map[string]interface{})
+			target, _ := stmt["target"].(map[string]interface{})
+			if _, ok := stmt["relation"].([]interface{}); !ok || target == nil {
+				return nil, fmt.Errorf("statement missing relation or target")
+			}
+			if pkg, ok := target["package_name"].(string); ok {
+				packages = append(packages, pkg)
+			} else if site, ok := target["site"].(string); ok {
+				packages = append(packages, site)
+			}
+		}
+		details["statements"] = len(statements)
+		details["targets"] = packages
+		return details, nil
+	}
+
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+	switch name {
+	case "openid-configuration":
+		for _, field := range []string{"issuer", "authorization_endpoint", "jwks_uri", "response_types_supported", "subject_types_supported", "id_token_signing_alg_values_supported"} {
+			if _, ok := obj[field]; !ok {
+				return nil, fmt.Errorf("required field %s is missing", field)
+			}
+		}
+		details["issuer"] = obj["issuer"]
+		details["token_endpoint"] = obj["token_endpoint"]
+		details["jwks_uri"] = obj["jwks_uri"]
+	case "apple-app-site-association":
+		var sections []string
+		for _, key := range []string{"applinks", "webcredentials", "appclips", "activitycontinuation"} {
+			if _, ok := obj[key]; ok {
+				sections = append(sections, key)
+			}
+		}
+		if len(sections) == 0 {
+			return nil, fmt.Errorf("no applinks, webcredentials, appclips or activitycontinuation section")
+		}
+		details["sections"] = sections
+	case "nodeinfo":
+		links, ok := obj["links"].([]interface{})
+		if !ok || len(links) == 0 {
+			return nil, fmt.Errorf("links array is missing")
+		}
+		var schemas []string
+		for _, l := range links {
+			link, _ := l.(map[string]interface{})
+			if rel, ok := link["rel"].(string); ok {
+				schemas = append(schemas, rel)
+			}
+		}
+		details["schemas"] = schemas
+	}
+	return details, nil
+}
+
+// CheckWellKnown audits security.txt and other /.well-known resources for a domain
+func (nc *NetChecker) CheckWellKnown(domain string) WellKnownInfo {
+	info := WellKnownInfo{Domain: domain}
+
+	// Clean domain
+	cleanDomain := strings.TrimPrefix(domain, "https://")
+	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
+	cleanDomain = strings.Split(cleanDomain, "/")[0]
+
+	resources := []struct {
+		name      string
+		host      string
+		path      string
+		httpsOnly bool
+	}{
+		{"change-password", cleanDomain, "/.well-known/change-password", false},
+		{"mta-sts.txt", "mta-sts." + strings.TrimPrefix(cleanDomain, "www."), "/.well-known/mta-sts.txt", true},
+		{"openid-configuration", cleanDomain, "/.well-known/openid-configuration", false},
+		{"apple-app-site-association", cleanDomain, "/.well-known/apple-app-site-association", true},
+		{"assetlinks.json", cleanDomain, "/.well-known/assetlinks.json", true},
+		{"nodeinfo", cleanDomain, "/.well-known/nodeinfo", false},
+	}
+
+	var wg sync.WaitGroup
+	info.Resources = make([]WellKnownResource, len(resources))
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		info.SecurityTxt = nc.CheckSecurityTxt(cleanDomain)
+	}()
+	for i, r := range resources {
+		wg.Add(1)
+		go func(i int, name, host, path string, httpsOnly bool) {
+			defer wg.Done()
+			resource := WellKnownResource{Name: name, URL: fmt.Sprintf("https://%s%s", host, path)}
+			res, err := nc.fetchWellKnown(host, path, httpsOnly)
+			if err != nil {
+				resource.Error = fmt.Sprintf("Failed to fetch %s: %v", name, err)
+				info.Resources[i] = resource
+				return
+			}
+			resource.URL = res.url
+			resource.StatusCode = res.status
+			resource.ContentType = res.contentType
+			resource.Size = len(res.body)
+			if res.finalURL != res.url {
+				resource.FinalURL = res.finalURL
+			}
+			resource.Exists = res.status == http.StatusOK
+			if !resource.Exists {
+				info.Resources[i] = resource
+				return
+			}
+
+			switch name {
+			case "change-password":
+				// Should redirect to the site's password change page (W3C Well-Known URL for Changing Passwords)
+				if resource.FinalURL == "" {
+					resource.ParseError = "change-password should redirect to the password change page"
+				}
+			case "mta-sts.txt":
+				if mediaType(res.contentType) != "text/plain" {
+					resource.ParseError = fmt.Sprintf("Content-Type must be text/plain, got %q", res.contentType)
+				}
+				details, err := parseMTASTSPolicy(string(res.body))
+				resource.Details = details
+				if err != nil {
+					resource.ParseError = err.Error()
+				}
+			default:
+				if mediaType(res.contentType) == "text/html" {
+					resource.ParseError = "Served as text/html; likely a soft 404 page"
+					break
+				}
+				details, err := parseJSONResource(name, res.body)
+				resource.Details = details
+				if err != nil {
+					resource.ParseError = err.Error()
+				} else if mediaType(res.contentType) != "application/json" && !strings.HasSuffix(mediaType(res.contentType), "+json") {
+					resource.ParseError = fmt.Sprintf("Content-Type should be application/json, got %q", res.contentType)
+				}
+			}
+			info.Resources[i] = resource
+		}(i, r.name, r.host, r.path, r.httpsOnly)
+	}
+	wg.Wait()
+
+	return info
+}
+
+func handleWellKnown(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/well-known", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	wellKnownInfo := checker.CheckWellKnown(domain)
+	if ttl, ok := routeTTL["/api/v1/well-known"]; ok {
+		apiCache.Set(key, wellKnownInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    wellKnownInfo,
+	})
+}
+
+// CheckSitemap checks sitemap.xml file for a domain
+func (nc *NetChecker) CheckSitemap(domain string) SitemapInfo {
+	info := SitemapInfo{Domain: domain}
+
+	// Clean domain
+	cleanDomain := strings.TrimPrefix(domain, "https://")
+	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
+	cleanDomain = strings.Split(cleanDomain, "/")[0]
+
+	// Common sitemap locations to try
+	sitemapPaths := []string{
+		"/sitemap.xml",
+		"/sitemap_index.xml",
+		"/sitemap-index.xml",
+		"/sitemaps.xml",
+	}
+
+	var sitemapURL string
+	var resp *http.Response
+	var err error
+
+	// Try each sitemap path
+	for _, path := range sitemapPaths {
+		sitemapURL = fmt.Sprintf("https://%s%s", cleanDomain, path)
+		resp, err = nc.httpClient.Get(sitemapURL)
+		if err == nil && resp.StatusCode == 200 {
+			info.SitemapURL = sitemapURL
+			break
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		// Try HTTP if HTTPS fails
+		sitemapURL = fmt.Sprintf("http://%s%s", cleanDomain, path)
+		resp, err = nc.httpClient.Get(sitemapURL)
+		if err == nil && resp.StatusCode == 200 {
+			info.SitemapURL = sitemapURL
+			break
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+
+	if resp == nil || err != nil {
+		info.Exists = false
+		info.Status = "Not Found"
+		info.Error = fmt.Sprintf("Failed to find sitemap: %v", err)
+		return info
+	}
+	defer resp.Body.Close()
+
+	info.Exists = true
+	info.Status = fmt.Sprintf("HTTP %d", resp.StatusCode)
+
+	// Read content
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to read response: %v", err)
+		return info
+	}
+
+	// Try to parse as sitemap index first
+	var sitemapIndex SitemapIndex
+	err = xml.Unmarshal(body, &sitemapIndex)
+	if err == nil && len(sitemapIndex.Sitemaps) > 0 {
+		info.IsSitemapIndex = true
+		info.URLCount = len(sitemapIndex.Sitemaps)
+		for _, s := range sitemapIndex.Sitemaps {
+			info.SubSitemaps = append(info.SubSitemaps, s.Loc)
+		}
+		return info
+	}
+
+	// Try to parse as regular sitemap
+	var sitemap Sitemap
+	err = xml.Unmarshal(body, &sitemap)
+	if err == nil && len(sitemap.URLs) > 0 {
+		info.IsSitemapIndex = false
+		info.URLCount = len(sitemap.URLs)
+
+		// Get sample URLs (first 10)
+		maxSamples := 10
+		if len(sitemap.URLs) < maxSamples {
+			maxSamples = len(sitemap.URLs)
+		}
+		for i := 0; i < maxSamples; i++ {
+			info.SampleURLs = append(info.SampleURLs, sitemap.URLs[i].Loc)
+			if sitemap.URLs[i].LastMod != "" {
+				info.LastModified = append(info.LastModified, sitemap.URLs[i].LastMod)
+			}
+		}
+		return info
+	}
+
+	// If parsing failed, still return that we found something
+	info.Error = "Sitemap found but could not parse XML structure"
+	return info
+}
+
+func handleSitemap(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Domain parameter is required",
+		})
+		return
+	}
+
+	key := cacheKey("/api/v1/sitemap", map[string]string{"domain": domain})
+	if v, ok := apiCache.Get(key); ok {
+		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
+		return
+	}
+	checker := NewNetChecker()
+	sitemapInfo := checker.CheckSitemap(domain)
+	if ttl, ok := routeTTL["/api/v1/sitemap"]; ok {
+		apiCache.Set(key, sitemapInfo, ttl)
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data:    sitemapInfo,
+	})
+}
+
+// HTMLProxyResponse represents HTML content fetched from a URL
+type HTMLProxyResponse struct {
+	URL    string `json:"url"`
+	HTML   string `json:"html"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// OGImageInfo represents Open Graph image and metadata information
+type OGImageInfo struct {
+	URL    string `json:"url"`
+	Domain string `json:"domain"`
+	Found  bool   `json:"found"`
+
+	// OG Image specific
+	ImageURL    string `json:"image_url,omitempty"`
+	ImageURLAlt string `json:"image_url_alt,omitempty"`
+	ImageSecure string `json:"image_secure,omitempty"`
+	ImageWidth  string `json:"image_width,omitempty"`
+	ImageHeight string `json:"image_height,omitempty"`
+	ImageType   string `json:"image_type,omitempty"`
+	Accessible  bool   `json:"accessible"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+
+	// OG Metadata
+	OGTitle       string `json:"og_title,omitempty"`
+	OGDescription string `json:"og_description,omitempty"`
+	OGType        string `json:"og_type,omitempty"`
+	OGURL         string `json:"og_url,omitempty"`
+	OGSiteName    string `json:"og_site_name,omitempty"`
+	OGLocale      string `json:"og_locale,omitempty"`
+
+	// Twitter Card metadata
+	TwitterCard        string `json:"twitter_card,omitempty"`
+	TwitterSite        string `json:"twitter_site,omitempty"`
+	TwitterCreator     string `json:"twitter_creator,omitempty"`
+	TwitterTitle       string `json:"twitter_title,omitempty"`
+	TwitterDescription string `json:"twitter_description,omitempty"`
+	TwitterImage       string `json:"twitter_image,omitempty"`
+	TwitterImageAlt    string `json:"twitter_image_alt,omitempty"`
+
+	// Standard meta tags
+	MetaTitle       string `json:"meta_title,omitempty"`
+	MetaDescription string `json:"meta_description,omitempty"`
+
+	// All detected tags
+	AllMetaTags    map[string]string `json:"all_meta_tags,omitempty"`
+	AllTwitterTags map[string]string `json:"all_twitter_tags,omitempty"`
+
+	// Detected web technologies
+	Technologies []Technology `json:"technologies,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+func handleHTMLProxy(c *gin.Context) {
+	url := c.Query("url")
+	if url == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "URL parameter is required",
+		})
+		return
+	}
+
+	// Clean and normalize URL
+	targetURL := url
+	if !strings.HasPrefix(targetURL, "https://") && !strings.HasPrefix(targetURL, "http://") {
+		targetURL = "https://" + targetURL
+	}
+
+	checker := NewNetChecker()
+	resp, err := checker.httpClient.Get(targetURL)
+	if err != nil {
+		// Try HTTP if HTTPS fails
+		if strings.HasPrefix(targetURL, "https://") {
+			targetURL = strings.Replace(targetURL, "https://", "http://", 1)
+			resp, err = checker.httpClient.Get(targetURL)
+		}
+		if err != nil {
+			c.JSON(http.StatusOK, APIResponse{
+				Success: true,
+				Data: HTMLProxyResponse{
+					URL:   targetURL,
+					Error: fmt.Sprintf("Failed to fetch: %v", err),
+				},
+			})
+			return
+		}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Data: HTMLProxyResponse{
+				URL:   targetURL,
+				Error: fmt.Sprintf("Failed to read response: %v", err),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success: true,
+		Data: HTMLProxyResponse{
+			URL:    targetURL,
+			HTML:   string(body),
+			Status: resp.StatusCode,
+		},
+	})
+}
+
+// CheckOGImage checks Open Graph image tags for a URL
+func (nc *NetChecker) CheckOGImage(url string) OGImageInfo {
+	info := OGImageInfo{URL: url}
+
+	// Clean and normalize URL
+	targetURL := url
+	if !strings.HasPrefix(targetURL, "https://") && !strings.HasPrefix(targetURL, "http://") {
+		targetURL = "https://" + targetURL
+	}
+
+	// Extract domain
+	cleanDomain := strings.TrimPrefix(targetURL, "https://")
+	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
+	cleanDomain = strings.Split(cleanDomain, "/")[0]
+	info.Domain = cleanDomain
+
+	// Fetch HTML content
+	resp, err := nc.httpClient.Get(targetURL)
+	if err != nil {
+		// Try HTTP if HTTPS fails
+		if strings.HasPrefix(targetURL, "https://") {
+			targetURL = strings.Replace(targetURL, "https://", "http://", 1)
+			resp, err = nc.httpClient.Get(targetURL)
+		}
+		if err != nil {
+			info.Error = fmt.Sprintf("Failed to fetch URL: %v", err)
+			return info
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		info.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		return info
+	}
+
+	// Read HTML content
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to read response: %v", err)
+		return info
+	}
+
+	htmlContent := string(body)
+	info.Technologies = DetectTechnologies(resp.Header, nil, htmlContent)
+	info.AllMetaTags = make(map[string]string)
+	info.AllTwitterTags = make(map[string]string)
+
+	// Parse all OG tags
+	allOGTagsRegex := regexp.MustCompile(`(?i)<meta\s+(?:property|name)=["'](og:[^"']+)["']\s+content=["']([^"']+)["']`)
+	matches := allOGTagsRegex.FindAllStringSubmatch(htmlContent, -1)
+	for _, match := range matches {
+		if len(match) >= 3 {
+			tagName := strings.ToLower(match[1])
+			tagValue := match[2]
+			info.AllMetaTags[tagName] = tagValue
+
+			// Extract specific OG fields
+			switch tagName {
+			case "og:title":
+				info.OGTitle = tagValue
+			case "og:description":
+				info.OGDescription = tagValue
+			case "og:type":
+				info.OGType = tagValue
+			case "og:url":
+				info.OGURL = tagValue
+			case "og:site_name":
+				info.OGSiteName = tagValue
+			case "og:locale":
+				info.OGLocale = tagValue
+			case "og:image":
+				info.ImageURL = tagValue
+				info.Found = true
+			case "og:image:url":
+				info.ImageURLAlt = tagValue
+			case "og:image:secure_url":
+				info.ImageSecure = tagValue
+			case "og:image:width":
+				info.ImageWidth = tagValue
+			case "og:image:height":
+				info.ImageHeight = tagValue
+			case "og:image:type":
+				info.ImageType = tagValue
+			}
+		}
+	}
+
+	// Parse all Twitter Card tags
+	allTwitterTagsRegex := regexp.MustCompile(`(?i)<meta\s+(?:property|name)=["'](twitter:[^"']+)["']\s+content=["']([^"']+)["']`)
+	twitterMatches := allTwitterTagsRegex.FindAllStringSubmatch(htmlContent, -1)
+	for _, match := range twitterMatches {
+		if len(match) >= 3 {
+			tagName := strings.ToLower(match[1])
+			tagValue := match[2]
+			info.AllTwitterTags[tagName] = tagValue
+
+			// Extract specific Twitter fields
+			switch tagName {
+			case "twitter:card":
+				info.TwitterCard = tagValue
+			case "twitter:site":
+				info.TwitterSite = tagValue
+			case "twitter:creator":
+				info.TwitterCreator = tagValue
+			case "twitter:title":
+				info.TwitterTitle = tagValue
+			case "twitter:description":
+				info.TwitterDescription = tagValue
+			case "twitter:image":
+				if !info.Found {
+					info.ImageURL = tagValue
+					info.Found = true
+				}
+				info.TwitterImage = tagValue
+			case "twitter:image:alt":
+				info.TwitterImageAlt = tagValue
+			}
+		}
+	}
+
+	// Extract standard meta tags (title and description)
+	metaTitleRegex := regexp.MustCompile(`(?i)<title>([^<]+)</title>`)
+	if match := metaTitleRegex.FindStringSubmatch(htmlContent); len(match) > 1 {
+		info.MetaTitle = strings.TrimSpace(match[1])
+	}
+
+	metaDescRegex := regexp.MustCompile(`(?i)<meta\s+name=["']description["']\s+content=["']([^"']+)["']`)
+	if match := metaDescRegex.FindStringSubmatch(htmlContent); len(match) > 1 {
+		info.MetaDescription = match[1]
+	}
+
+	// If still no image found, try standard meta tags
+	if !info.Found {
+		standardImageRegex := regexp.MustCompile(`(?i)<link\s+rel=["']image_src["']\s+href=["']([^"']+)["']`)
+		if match := standardImageRegex.FindStringSubmatch(htmlContent); len(match) > 1 {
+			info.ImageURL = match[1]
+			info.Found = true
+		}
+	}
+
+	// If we found an image URL, check if it's accessible
+	if info.Found && info.ImageURL != "" {
+		// Resolve relative URLs
+		imageURL := info.ImageURL
+		if strings.HasPrefix(imageURL, "//") {
+			imageURL = "https:" + imageURL
+		} else if strings.HasPrefix(imageURL, "/") {
+			imageURL = fmt.Sprintf("https://%s%s", cleanDomain, imageURL)
+		} else if !strings.HasPrefix(imageURL, "http://") && !strings.HasPrefix(imageURL, "https://") {
+			// Relative URL without leading slash
+			if strings.HasSuffix(targetURL, "/") {
+				imageURL = targetURL + imageURL
+			} else {
+				imageURL = targetURL + "/" + imageURL
+			}
+		}
+
+		// Try to fetch the image
+		imgResp, imgErr := nc.httpClient.Get(imageURL)
+		if imgErr != nil {
+			// Try HTTP if HTTPS fails
+			if strings.HasPrefix(imageURL, "https://") {
+				imageURLHTTP := strings.Replace(imageURL, "https://", "http://", 1)
+				var imgRespHTTP *http.Response
+				imgRespHTTP, imgErr = nc.httpClient.Get(imageURLHTTP)
+				if imgErr == nil {
+					imgResp = imgRespHTTP
+				}
+			}
+		}
+
+		if imgErr == nil && imgResp != nil {
+			defer imgResp.Body.Close()
+			info.Accessible = true
+			info.Status = imgResp.StatusCode
+			info.ContentType = imgResp.Header.Get("Content-Type")
+			info.Size = imgResp.ContentLength
+		} else {
+			info.Accessible = false
+			if imgErr != nil {
+				info.Error = fmt.Sprintf("Image not accessible: %v", imgErr)
+			} else {
+				info.Error = "Image not accessible"
+			}
 		}
-	} else {
-		result.SSL.Domain = cleanDomain
-		result.SSL.Error = "No TLS certificate found"
 	}
 
-	return result
+	return info
 }
 
-// API Handlers
-
-func handleSSL(c *gin.Context) {
+func handleOGImage(c *gin.Context) {
+	url := c.Query("url")
 	domain := c.Query("domain")
-	if domain == "" {
+
+	// Support both url and domain parameters
+	targetURL := url
+	if targetURL == "" && domain != "" {
+		targetURL = domain
+	}
+
+	if targetURL == "" {
 		c.JSON(http.StatusBadRequest, APIResponse{
 			Success: false,
-			Error:   "Domain parameter is required",
+			Error:   "URL or domain parameter is required",
 		})
 		return
 	}
 
-	// cache
-	key := cacheKey("/api/v1/ssl", map[string]string{"domain": domain})
+	key := cacheKey("/api/v1/og-image", map[string]string{"url": targetURL})
 	if v, ok := apiCache.Get(key); ok {
 		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
 		return
 	}
 
 	checker := NewNetChecker()
-	sslInfo := checker.CheckSSL(domain)
-	if ttl, ok := routeTTL["/api/v1/ssl"]; ok {
-		apiCache.Set(key, sslInfo, ttl)
+	ogInfo := checker.CheckOGImage(targetURL)
+	if ttl, ok := routeTTL["/api/v1/og-image"]; ok {
+		apiCache.Set(key, ogInfo, ttl)
 	}
 
 	c.JSON(http.StatusOK, APIResponse{
 		Success: true,
-		Data:    sslInfo,
+		Data:    ogInfo,
 	})
 }
 
-func handleHTTP3(c *gin.Context) {
-	domain := c.Query("domain")
-	if domain == "" {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Domain parameter is required",
-		})
-		return
-	}
+// -----------------------------
+// RDAP / WHOIS registration lookup
+// -----------------------------
 
-	key := cacheKey("/api/v1/http3", map[string]string{"domain": domain})
-	if v, ok := apiCache.Get(key); ok {
-		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
-		return
-	}
-	checker := NewNetChecker()
-	http3Info := checker.CheckHTTP3(domain)
-	if ttl, ok := routeTTL["/api/v1/http3"]; ok {
-		apiCache.Set(key, http3Info, ttl)
-	}
+// WhoisInfo represents normalized registration data for a domain, IP or ASN
+type WhoisInfo struct {
+	Query           string   `json:"query"`
+	Type            string   `json:"type"`             // domain, ip, asn
+	Source          string   `json:"source,omitempty"` // rdap, whois
+	Server          string   `json:"server,omitempty"`
+	Handle          string   `json:"handle,omitempty"`
+	Name            string   `json:"name,omitempty"`
+	Registrar       string   `json:"registrar,omitempty"`
+	RegistrantOrg   string   `json:"registrant_organization,omitempty"`
+	Country         string   `json:"country,omitempty"`
+	Created         string   `json:"created,omitempty"`
+	Updated         string   `json:"updated,omitempty"`
+	Expires         string   `json:"expires,omitempty"`
+	DaysUntilExpiry *int     `json:"days_until_expiry,omitempty"`
+	Status          []string `json:"status,omitempty"`
+	Nameservers     []string `json:"nameservers,omitempty"`
+	AbuseEmails     []string `json:"abuse_emails,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"`
+	Error           string   `json:"error,omitempty"`
+}
 
-	c.JSON(http.StatusOK, APIResponse{
-		Success: true,
-		Data:    http3Info,
-	})
+// RDAPBootstrap represents an IANA RDAP bootstrap registry file (RFC 9224)
+type RDAPBootstrap struct {
+	Version  string       `json:"version"`
+	Services [][][]string `json:"services"`
 }
 
-func handleDNS(c *gin.Context) {
-	domain := c.Query("domain")
-	if domain == "" {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Domain parameter is required",
-		})
-		return
-	}
-	key := cacheKey("/api/v1/dns", map[string]string{"domain": domain})
-	if v, ok := apiCache.Get(key); ok {
-		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
-		return
-	}
-	checker := NewNetChecker()
-	dnsInfo := checker.CheckDNS(domain)
-	if ttl, ok := routeTTL["/api/v1/dns"]; ok {
-		apiCache.Set(key, dnsInfo, ttl)
-	}
+// rdapEntity, rdapEvent and rdapResponse cover the subset of RFC 9083 we normalize
+type rdapEntity struct {
+	Handle     string        `json:"handle"`
+	Roles      []string      `json:"roles"`
+	VCardArray []interface{} `json:"vcardArray"`
+	Entities   []rdapEntity  `json:"entities"`
+}
 
-	c.JSON(http.StatusOK, APIResponse{
-		Success: true,
-		Data:    dnsInfo,
-	})
+type rdapEvent struct {
+	Action string `json:"eventAction"`
+	Date   string `json:"eventDate"`
 }
 
-func handleIP(c *gin.Context) {
-	// Accept both 'ip' and 'domain' parameters for flexibility
-	input := c.Query("ip")
-	if input == "" {
-		input = c.Query("domain")
-	}
+type rdapLink struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+	Type string `json:"type"`
+}
 
-	if input == "" {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "IP or domain parameter is required",
-		})
-		return
-	}
+type rdapResponse struct {
+	Handle      string       `json:"handle"`
+	Name        string       `json:"name"`
+	LDHName     string       `json:"ldhName"`
+	Country     string       `json:"country"`
+	Status      []string     `json:"status"`
+	Events      []rdapEvent  `json:"events"`
+	Entities    []rdapEntity `json:"entities"`
+	Links       []rdapLink   `json:"links"`
+	Nameservers []struct {
+		LDHName string `json:"ldhName"`
+	} `json:"nameservers"`
+}
 
-	key := cacheKey("/api/v1/ip", map[string]string{"input": input})
-	if v, ok := apiCache.Get(key); ok {
-		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
-		return
-	}
-	checker := NewNetChecker()
-	ipInfo := checker.CheckIP(input)
-	if ttl, ok := routeTTL["/api/v1/ip"]; ok {
-		apiCache.Set(key, ipInfo, ttl)
-	}
+// rdapBootstrapTTL is how long a loaded bootstrap file is used before it is reloaded.
+// IANA updates the registry files as TLDs and address blocks move between registries.
+const rdapBootstrapTTL = 24 * time.Hour
 
-	c.JSON(http.StatusOK, APIResponse{
-		Success: true,
-		Data:    ipInfo,
-	})
+type rdapBootstrapEntry struct {
+	bootstrap *RDAPBootstrap
+	loaded    time.Time
 }
 
-func handleMyIP(c *gin.Context) {
-	// Get the real client IP from the request
-	clientIP := getRealClientIP(c)
+var (
+	rdapBootstrapMu    sync.Mutex
+	rdapBootstrapCache = make(map[string]rdapBootstrapEntry)
+)
 
-	if clientIP == "" {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Could not determine client IP address",
-		})
-		return
-	}
+// loadRDAPBootstrap loads a bootstrap file (dns, ipv4, ipv6, asn) from RDAP_BOOTSTRAP_DIR,
+// falling back to the IANA copy when no local file exists. A stale copy is kept in use
+// if reloading fails.
+func (nc *NetChecker) loadRDAPBootstrap(kind string) (*RDAPBootstrap, error) {
+	rdapBootstrapMu.Lock()
+	defer rdapBootstrapMu.Unlock()
 
-	// Use client IP as cache key (per user)
-	key := cacheKey("/api/v1/my-ip", map[string]string{"ip": clientIP})
-	if v, ok := apiCache.Get(key); ok {
-		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
-		return
+	cached, ok := rdapBootstrapCache[kind]
+	if ok && time.Since(cached.loaded) < rdapBootstrapTTL {
+		return cached.bootstrap, nil
 	}
 
-	// Get geolocation information from MaxMind
-	country, region, city, isp, organization, timezone := lookupGeoIP(clientIP)
-
-	// Create IP info response directly without the slow connection test
-	// The connection test in CheckIP() is unnecessary for my-ip and causes delays
-	ipInfo := IPInfo{
-		Input:        "Your IP: " + clientIP,
-		IsDomain:     false,
-		IP:           clientIP,
-		Country:      country,
-		Region:       region,
-		City:         city,
-		ISP:          isp,
-		Organization: organization,
-		Timezone:     timezone,
+	b, err := nc.readRDAPBootstrap(kind)
+	if err != nil {
+		if ok {
+			fmt.Printf("Warning: keeping stale RDAP bootstrap %s: %v\n", kind, err)
+			return cached.bootstrap, nil
+		}
+		return nil, err
 	}
+	rdapBootstrapCache[kind] = rdapBootstrapEntry{bootstrap: b, loaded: time.Now()}
+	return b, nil
+}
 
-	if ttl, ok := routeTTL["/api/v1/my-ip"]; ok {
-		apiCache.Set(key, ipInfo, ttl)
+// readRDAPBootstrap reads a bootstrap file from disk or from RDAP_BOOTSTRAP_URL
+func (nc *NetChecker) readRDAPBootstrap(kind string) (*RDAPBootstrap, error) {
+	path := fmt.Sprintf("%s/%s.json", getenvDefault("RDAP_BOOTSTRAP_DIR", "rdap"), kind)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		bootstrapURL := fmt.Sprintf("%s/%s.json", strings.TrimSuffix(getenvDefault("RDAP_BOOTSTRAP_URL", "https://data.iana.org/rdap"), "/"), kind)
+		resp, err := nc.httpClient.Get(bootstrapURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch RDAP bootstrap %s: %v", kind, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch RDAP bootstrap %s: HTTP %d", kind, resp.StatusCode)
+		}
+		data, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RDAP bootstrap %s: %v", kind, err)
+		}
 	}
 
-	c.JSON(http.StatusOK, APIResponse{
-		Success: true,
-		Data:    ipInfo,
-	})
+	var b RDAPBootstrap
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse RDAP bootstrap %s: %v", kind, err)
+	}
+	return &b, nil
 }
 
-func handleWebSettings(c *gin.Context) {
-	domain := c.Query("domain")
-	if domain == "" {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Domain parameter is required",
-		})
-		return
+// rdapServerFor finds the RDAP base URL responsible for a query
+func (nc *NetChecker) rdapServerFor(queryType, query string) (string, error) {
+	kind := "dns"
+	switch queryType {
+	case "ip":
+		kind = "ipv6"
+		if net.ParseIP(query).To4() != nil {
+			kind = "ipv4"
+		}
+	case "asn":
+		kind = "asn"
 	}
 
-	key := cacheKey("/api/v1/web-settings", map[string]string{"domain": domain})
-	if v, ok := apiCache.Get(key); ok {
-		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
-		return
+	bootstrap, err := nc.loadRDAPBootstrap(kind)
+	if err != nil {
+		return "", err
 	}
-	checker := NewNetChecker()
-	webInfo := checker.CheckWebSettings(domain)
-	if ttl, ok := routeTTL["/api/v1/web-settings"]; ok {
-		apiCache.Set(key, webInfo, ttl)
+
+	best, bestLen := "", -1
+	for _, service := range bootstrap.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+		for _, entry := range service[0] {
+			matchLen := -1
+			switch kind {
+			case "dns":
+				entry = strings.ToLower(entry)
+				if query == entry || strings.HasSuffix(query, "."+entry) {
+					matchLen = len(entry)
+				}
+			case "ipv4", "ipv6":
+				_, network, err := net.ParseCIDR(entry)
+				if err == nil && network.Contains(net.ParseIP(query)) {
+					matchLen, _ = network.Mask.Size()
+				}
+			case "asn":
+				bounds := strings.SplitN(entry, "-", 2)
+				lo, err1 := strconv.ParseUint(bounds[0], 10, 32)
+				hi, err2 := strconv.ParseUint(bounds[len(bounds)-1], 10, 32)
+				n, err3 := strconv.ParseUint(query, 10, 32)
+				if err1 == nil && err2 == nil && err3 == nil && n >= lo && n <= hi {
+					matchLen = 0
+				}
+			}
+			if matchLen > bestLen {
+				bestLen = matchLen
+				best = pickRDAPURL(service[1])
+			}
+		}
 	}
 
-	c.JSON(http.StatusOK, APIResponse{
-		Success: true,
-		Data:    webInfo,
-	})
+	if best == "" {
+		return "", fmt.Errorf("no RDAP service found for %s", query)
+	}
+	return best, nil
 }
 
-func handleEmailConfig(c *gin.Context) {
-	domain := c.Query("domain")
-	if domain == "" {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Domain parameter is required",
-		})
-		return
+// pickRDAPURL prefers an HTTPS base URL and ensures a trailing slash
+func pickRDAPURL(urls []string) string {
+	chosen := urls[0]
+	for _, u := range urls {
+		if strings.HasPrefix(u, "https://") {
+			chosen = u
+			break
+		}
 	}
+	if !strings.HasSuffix(chosen, "/") {
+		chosen += "/"
+	}
+	return chosen
+}
 
-	key := cacheKey("/api/v1/email-config", map[string]string{"domain": domain})
-	if v, ok := apiCache.Get(key); ok {
-		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
-		return
+// fetchRDAP performs a single RDAP query
+func fetchRDAP(client *http.Client, rdapURL string) (*rdapResponse, error) {
+	req, err := http.NewRequest("GET", rdapURL, nil)
+	if err != nil {
+		return nil, err
 	}
-	checker := NewNetChecker()
-	emailInfo := checker.CheckEmailConfig(domain)
-	if ttl, ok := routeTTL["/api/v1/email-config"]; ok {
-		apiCache.Set(key, emailInfo, ttl)
+	req.Header.Set("Accept", "application/rdap+json, application/json")
+	req.Header.Set("User-Agent", "NetCheck-API/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	c.JSON(http.StatusOK, APIResponse{
-		Success: true,
-		Data:    emailInfo,
-	})
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RDAP server returned HTTP %d", resp.StatusCode)
+	}
+
+	var out rdapResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to parse RDAP response: %v", err)
+	}
+	return &out, nil
 }
 
-// DNS servers with blocklists
-var blocklistDNSServers = []DNSServer{
-	{Name: "AdGuard", IP: "176.103.130.130"},
-	{Name: "AdGuard Family", IP: "176.103.130.132"},
-	{Name: "CleanBrowsing Adult", IP: "185.228.168.10"},
-	{Name: "CleanBrowsing Family", IP: "185.228.168.168"},
-	{Name: "CleanBrowsing Security", IP: "185.228.168.9"},
-	{Name: "CloudFlare", IP: "1.1.1.1"},
-	{Name: "CloudFlare Family", IP: "1.1.1.3"},
-	{Name: "Comodo Secure", IP: "8.26.56.26"},
-	{Name: "Google DNS", IP: "8.8.8.8"},
-	{Name: "Neustar Family", IP: "156.154.70.3"},
-	{Name: "Neustar Protection", IP: "156.154.70.2"},
-	{Name: "Norton Family", IP: "199.85.126.20"},
-	{Name: "OpenDNS", IP: "208.67.222.222"},
-	{Name: "OpenDNS Family", IP: "208.67.222.123"},
-	{Name: "Quad9", IP: "9.9.9.9"},
-	{Name: "Yandex Family", IP: "77.88.8.7"},
-	{Name: "Yandex Safe", IP: "77.88.8.88"},
+// vcardValue returns the first value of a vCard property (jCard, RFC 7095)
+func vcardValue(vcard []interface{}, property string) string {
+	if len(vcard) < 2 {
+		return ""
+	}
+	props, ok := vcard[1].([]interface{})
+	if !ok {
+		return ""
+	}
+	for _, p := range props {
+		fields, ok := p.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+		if name, _ := fields[0].(string); name != property {
+			continue
+		}
+		if value, ok := fields[3].(string); ok {
+			return value
+		}
+	}
+	return ""
 }
 
-// Known block IPs that DNS servers return when blocking domains
-var knownBlockIPs = []string{
-	"146.112.61.106", // OpenDNS
-	"185.228.168.10", // CleanBrowsing
-	"8.26.56.26",     // Comodo
-	"208.69.38.170",  // OpenDNS
-	"208.69.39.170",  // OpenDNS
-	"208.67.222.222", // OpenDNS
-	"208.67.222.123", // OpenDNS FamilyShield
-	"199.85.126.10",  // Norton
-	"199.85.126.20",  // Norton Family
-	"156.154.70.22",  // Neustar
-	"77.88.8.7",      // Yandex
-}
+// applyRDAP merges an RDAP response into the normalized info
+func applyRDAP(info *WhoisInfo, r *rdapResponse) {
+	if info.Handle == "" {
+		info.Handle = r.Handle
+	}
+	if info.Name == "" {
+		info.Name = r.Name
+		if info.Name == "" {
+			info.Name = strings.ToLower(r.LDHName)
+		}
+	}
+	if info.Country == "" {
+		info.Country = r.Country
+	}
+	for _, s := range r.Status {
+		if !contains(info.Status, s) {
+			info.Status = append(info.Status, s)
+		}
+	}
+	for _, ns := range r.Nameservers {
+		name := strings.ToLower(strings.TrimSuffix(ns.LDHName, "."))
+		if name != "" && !contains(info.Nameservers, name) {
+			info.Nameservers = append(info.Nameservers, name)
+		}
+	}
+	for _, ev := range r.Events {
+		date := normalizeWhoisDate(ev.Date)
+		switch ev.Action {
+		case "registration":
+			info.Created = date
+		case "expiration":
+			info.Expires = date
+		case "last changed":
+			info.Updated = date
+		}
+	}
 
-// contains checks if a string slice contains a value
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
+	var walk func(entities []rdapEntity)
+	walk = func(entities []rdapEntity) {
+		for _, e := range entities {
+			for _, role := range e.Roles {
+				switch role {
+				case "registrar":
+					if info.Registrar == "" {
+						info.Registrar = vcardValue(e.VCardArray, "fn")
+					}
+				case "registrant":
+					if info.RegistrantOrg == "" {
+						info.RegistrantOrg = vcardValue(e.VCardArray, "org")
+						if info.RegistrantOrg == "" {
+							info.RegistrantOrg = vcardValue(e.VCardArray, "fn")
+						}
+					}
+				case "abuse":
+					if email := vcardValue(e.VCardArray, "email"); email != "" && !contains(info.AbuseEmails, email) {
+						info.AbuseEmails = append(info.AbuseEmails, email)
+					}
+				}
+			}
+			walk(e.Entities)
 		}
 	}
-	return false
+	walk(r.Entities)
 }
 
-// isDomainBlockedByServer checks if a domain is blocked by a specific DNS server
-func (nc *NetChecker) isDomainBlockedByServer(domain, serverIP string) bool {
-	// Create a custom DNS resolver for the specific server
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// whoisDateLayouts covers the date formats commonly seen in RDAP and port-43 responses
+var whoisDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02",
+	"2006.01.02",
+	"02-Jan-2006",
+	"2006/01/02",
+}
 
-	// Try to resolve the domain using the server
-	resolver := &net.Resolver{
-		PreferGo: true,
-		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-			d := net.Dialer{}
-			return d.DialContext(ctx, "udp", serverIP+":53")
-		},
+// normalizeWhoisDate converts a registry date to RFC 3339, keeping the raw value if unknown
+func normalizeWhoisDate(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range whoisDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
 	}
+	return value
+}
 
-	// Resolve A records
-	addrs, err := resolver.LookupIPAddr(ctx, domain)
+// whoisServer is a port-43 WHOIS or RWhois (RFC 2167) server address
+type whoisServer struct {
+	addr   string
+	rwhois bool
+}
+
+// parseWhoisServer normalizes "host", "host:port", "whois://host" and "rwhois://host:port/"
+func parseWhoisServer(value string) whoisServer {
+	server := whoisServer{}
+	port := "43"
+	switch {
+	case strings.HasPrefix(value, "rwhois://"):
+		server.rwhois = true
+		port = "4321"
+		value = strings.TrimPrefix(value, "rwhois://")
+	case strings.HasPrefix(value, "whois://"):
+		value = strings.TrimPrefix(value, "whois://")
+	}
+	value = strings.TrimSpace(strings.Split(value, "/")[0])
+	if value == "" {
+		return server
+	}
+	if _, _, err := net.SplitHostPort(value); err != nil {
+		value = net.JoinHostPort(value, port)
+	}
+	server.addr = value
+	return server
+}
+
+// queryWhois performs a raw port-43 WHOIS or RWhois query
+func queryWhois(server whoisServer, query string) (string, error) {
+	conn, err := net.DialTimeout("tcp", server.addr, 5*time.Second)
 	if err != nil {
-		// If DNS resolution fails, it might indicate the domain is blocked
-		return true
+		return "", err
 	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
 
-	// Check if any returned IP is a known block IP
-	for _, addr := range addrs {
-		if contains(knownBlockIPs, addr.IP.String()) {
-			return true
+	if !server.rwhois {
+		if _, err := conn.Write([]byte(query + "\r\n")); err != nil {
+			return "", err
 		}
+		body, err := io.ReadAll(io.LimitReader(conn, 1<<20))
+		if err != nil {
+			return "", err
+		}
+		return string(body), nil
 	}
 
-	return false
+	// RWhois keeps the session open, so read up to the %ok/%error that ends the
+	// response and turn "class:attribute:value" lines into "attribute: value"
+	if _, err := conn.Write([]byte(query + "\r\n-quit\r\n")); err != nil {
+		return "", err
+	}
+	tp := textproto.NewReader(bufio.NewReader(io.LimitReader(conn, 1<<20)))
+	var b strings.Builder
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			if b.Len() > 0 && errors.Is(err, io.EOF) {
+				return b.String(), nil
+			}
+			return "", err
+		}
+		switch {
+		case strings.HasPrefix(line, "%ok"):
+			return b.String(), nil
+		case strings.HasPrefix(line, "%error"):
+			return "", fmt.Errorf("RWhois server returned %s", strings.TrimSpace(strings.TrimPrefix(line, "%error")))
+		case strings.HasPrefix(line, "%"):
+			continue
+		}
+		if parts := strings.SplitN(line, ":", 3); len(parts) == 3 {
+			attribute := strings.TrimSuffix(parts[1], ";I")
+			fmt.Fprintf(&b, "%s: %s\n", attribute, parts[2])
+		}
+	}
 }
 
-// CheckBlocklist checks if a domain is blocked by various DNS servers
-func (nc *NetChecker) CheckBlocklist(domain string) BlocklistInfo {
-	info := BlocklistInfo{Domain: domain}
+// parseWhoisText extracts normalized fields from a port-43 WHOIS response
+func parseWhoisText(info *WhoisInfo, text string) (referral string) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "%") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		field := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+		if value == "" {
+			continue
+		}
 
-	// Clean domain
-	cleanDomain := strings.TrimPrefix(domain, "https://")
-	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
-	cleanDomain = strings.Split(cleanDomain, "/")[0]
+		switch field {
+		case "refer", "whois", "registrar whois server", "referralserver":
+			referral = value
+		case "registrar", "sponsoring registrar":
+			if info.Registrar == "" {
+				info.Registrar = value
+			}
+		case "registrant organization", "registrant organisation", "org-name", "orgname", "organization", "owner":
+			if info.RegistrantOrg == "" {
+				info.RegistrantOrg = value
+			}
+		case "creation date", "created", "registered", "regdate", "registration time":
+			if info.Created == "" {
+				info.Created = normalizeWhoisDate(value)
+			}
+		case "updated date", "last-modified", "changed", "updated", "last modified":
+			if info.Updated == "" {
+				info.Updated = normalizeWhoisDate(value)
+			}
+		case "registry expiry date", "registrar registration expiration date", "expiration date", "expiry date", "expires", "paid-till":
+			if info.Expires == "" {
+				info.Expires = normalizeWhoisDate(value)
+			}
+		case "domain status", "status", "state":
+			status := strings.Fields(value)[0]
+			if !contains(info.Status, status) {
+				info.Status = append(info.Status, status)
+			}
+		case "name server", "nserver", "nameserver", "nameservers":
+			ns := strings.ToLower(strings.TrimSuffix(strings.Fields(value)[0], "."))
+			if !contains(info.Nameservers, ns) {
+				info.Nameservers = append(info.Nameservers, ns)
+			}
+		case "registrar abuse contact email", "orgabuseemail", "abuse-mailbox", "abuse contact":
+			if !contains(info.AbuseEmails, value) {
+				info.AbuseEmails = append(info.AbuseEmails, value)
+			}
+		case "netname", "network-name", "as-name", "asname":
+			if info.Name == "" {
+				info.Name = value
+			}
+		case "country":
+			if info.Country == "" {
+				info.Country = value
+			}
+		}
+	}
+	return referral
+}
 
-	var results []BlocklistResult
+// classifyWhoisQuery determines whether a query is a domain, IP or ASN and normalizes it
+func classifyWhoisQuery(input string) (queryType, query string) {
+	query = strings.TrimPrefix(input, "https://")
+	query = strings.TrimPrefix(query, "http://")
+	query = strings.ToLower(strings.TrimSpace(strings.Split(query, "/")[0]))
 
-	// Check against each DNS server
-	for _, server := range blocklistDNSServers {
-		isBlocked := nc.isDomainBlockedByServer(cleanDomain, server.IP)
-		results = append(results, BlocklistResult{
-			Server:    server.Name,
-			ServerIP:  server.IP,
-			IsBlocked: isBlocked,
-		})
+	if ip := net.ParseIP(query); ip != nil {
+		return "ip", ip.String()
+	}
+	asn := strings.TrimPrefix(query, "as")
+	if _, err := strconv.ParseUint(asn, 10, 32); err == nil {
+		return "asn", asn
+	}
+
+	query = strings.TrimSuffix(query, ".")
+	if registered, err := publicsuffix.EffectiveTLDPlusOne(query); err == nil {
+		query = registered
+	}
+	return "domain", query
+}
+
+// CheckWhois looks up registration data via RDAP, falling back to port-43 WHOIS
+func (nc *NetChecker) CheckWhois(input string) WhoisInfo {
+	queryType, query := classifyWhoisQuery(input)
+	info := WhoisInfo{Query: query, Type: queryType}
+
+	rdapErr := func() error {
+		base, err := nc.rdapServerFor(queryType, query)
+		if err != nil {
+			return err
+		}
+		path := map[string]string{"domain": "domain/", "ip": "ip/", "asn": "autnum/"}[queryType]
+		rdapURL := base + path + query
+		r, err := fetchRDAP(nc.httpClient, rdapURL)
+		if err != nil {
+			return err
+		}
+		info.Source = "rdap"
+		info.Server = rdapURL
+		applyRDAP(&info, r)
+
+		// Thin registries point at the registrar's RDAP service for registrant data.
+		// The link comes from a remote response, so it may only reach public hosts.
+		for _, link := range r.Links {
+			if link.Rel == "related" && strings.Contains(link.Type, "rdap") && link.Href != rdapURL {
+				related, err := fetchRDAP(nc.publicHTTPClient(), link.Href)
+				if err != nil {
+					info.Warnings = append(info.Warnings, fmt.Sprintf("Related RDAP lookup %s failed: %v", link.Href, err))
+					break
+				}
+				applyRDAP(&info, related)
+				break
+			}
+		}
+		return nil
+	}()
+
+	if rdapErr != nil {
+		whoisQuery := query
+		if queryType == "asn" {
+			whoisQuery = "AS" + query
+		}
+		server := parseWhoisServer(getenvDefault("WHOIS_SERVER", "whois.iana.org"))
+		// Follow referrals from IANA down to the authoritative server, keeping what
+		// earlier hops returned if a referral cannot be queried
+		for hops := 0; hops < 3 && server.addr != ""; hops++ {
+			if hops > 0 {
+				host, _, _ := net.SplitHostPort(server.addr)
+				if _, err := resolveTarget(host); err != nil {
+					info.Warnings = append(info.Warnings, fmt.Sprintf("Referral to %s refused: %v", server.addr, err))
+					break
+				}
+			}
+			text, err := queryWhois(server, whoisQuery)
+			if err != nil {
+				if hops == 0 {
+					info.Error = fmt.Sprintf("RDAP lookup failed (%v); WHOIS lookup failed: %v", rdapErr, err)
+					return info
+				}
+				info.Warnings = append(info.Warnings, fmt.Sprintf("Referral to %s failed: %v", server.addr, err))
+				break
+			}
+			info.Source = "whois"
+			info.Server = server.addr
+			referral := parseWhoisServer(parseWhoisText(&info, text))
+			if referral.addr == "" || referral == server {
+				break
+			}
+			server = referral
+		}
+	}
+
+	if info.Expires != "" {
+		if t, err := time.Parse(time.RFC3339, info.Expires); err == nil {
+			days := int(time.Until(t).Hours() / 24)
+			info.DaysUntilExpiry = &days
+		}
 	}
 
-	info.Results = results
 	return info
 }
 
-func handleBlocklist(c *gin.Context) {
-	domain := c.Query("domain")
-	if domain == "" {
+func handleWhois(c *gin.Context) {
+	query := c.Query("query")
+	if query == "" {
+		query = c.Query("domain")
+	}
+	if query == "" {
+		query = c.Query("ip")
+	}
+	if query == "" {
 		c.JSON(http.StatusBadRequest, APIResponse{
 			Success: false,
-			Error:   "Domain parameter is required",
+			Error:   "Query, domain or ip parameter is required",
 		})
 		return
 	}
 
-	key := cacheKey("/api/v1/blocklist", map[string]string{"domain": domain})
+	key := cacheKey("/api/v1/whois", map[string]string{"query": query})
 	if v, ok := apiCache.Get(key); ok {
 		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
 		return
 	}
 	checker := NewNetChecker()
-	blocklistInfo := checker.CheckBlocklist(domain)
-	if ttl, ok := routeTTL["/api/v1/blocklist"]; ok {
-		apiCache.Set(key, blocklistInfo, ttl)
+	whoisInfo := checker.CheckWhois(query)
+	if ttl, ok := routeTTL["/api/v1/whois"]; ok && whoisInfo.Error == "" {
+		apiCache.Set(key, whoisInfo, ttl)
 	}
 
 	c.JSON(http.StatusOK, APIResponse{
 		Success: true,
-		Data:    blocklistInfo,
+		Data:    whoisInfo,
 	})
 }
 
-func handleHSTS(c *gin.Context) {
-	domain := c.Query("domain")
-	if domain == "" {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Domain parameter is required",
-		})
-		return
-	}
-
-	checker := NewNetChecker()
+// -----------------------------
+// TCP port reachability scanner
+// -----------------------------
 
-	// Get web settings which includes HSTS
-	webInfo := checker.CheckWebSettings(domain)
+// Port scan limits keep the scanner from being usable as a general-purpose attack tool
+const (
+	maxScanPorts       = 32
+	scanConcurrency    = 8
+	defaultScanTimeout = 2 * time.Second
+	maxScanTimeout     = 5 * time.Second
+	maxBannerBytes     = 256
+)
 
-	// Return just the HSTS info
-	hstsInfo := HSTSInfo{
-		Enabled:           webInfo.HSTS.Enabled,
-		MaxAge:            webInfo.HSTS.MaxAge,
-		IncludeSubDomains: webInfo.HSTS.IncludeSubDomains,
-		Preload:           webInfo.HSTS.Preload,
-		Directive:         webInfo.HSTS.Directive,
-		Details:           webInfo.HSTS.Details,
-	}
+// defaultScanPorts are probed when the caller does not supply a port list
+var defaultScanPorts = []int{21, 22, 25, 53, 80, 110, 143, 443, 465, 587, 993, 995, 3306, 3389, 5432, 8080, 8443}
+
+// knownServices maps well-known ports to service names
+var knownServices = map[int]string{
+	21:   "ftp",
+	22:   "ssh",
+	23:   "telnet",
+	25:   "smtp",
+	53:   "dns",
+	80:   "http",
+	110:  "pop3",
+	143:  "imap",
+	443:  "https",
+	465:  "smtps",
+	587:  "submission",
+	993:  "imaps",
+	995:  "pop3s",
+	3306: "mysql",
+	3389: "rdp",
+	5432: "postgresql",
+	6379: "redis",
+	8000: "http-alt",
+	8080: "http-alt",
+	8443: "https-alt",
+}
 
-	c.JSON(http.StatusOK, APIResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"domain": domain,
-			"hsts":   hstsInfo,
-		},
-	})
+// PortResult represents the probe result for a single TCP port
+type PortResult struct {
+	Port      int    `json:"port"`
+	Service   string `json:"service,omitempty"`
+	Status    string `json:"status"` // open, closed, filtered
+	LatencyMs int64  `json:"latency_ms,omitempty"`
+	Banner    string `json:"banner,omitempty"`
 }
 
-// CheckRobotsTxt checks robots.txt file for a domain
-func (nc *NetChecker) CheckRobotsTxt(domain string) RobotsTxtInfo {
-	info := RobotsTxtInfo{Domain: domain}
+// PortScanInfo represents TCP port reachability information
+type PortScanInfo struct {
+	Host      string       `json:"host"`
+	IP        string       `json:"ip"`
+	Ports     []PortResult `json:"ports"`
+	OpenCount int          `json:"open_count"`
+	Error     string       `json:"error,omitempty"`
+}
 
-	// Clean domain
-	cleanDomain := strings.TrimPrefix(domain, "https://")
-	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
-	cleanDomain = strings.Split(cleanDomain, "/")[0]
+// isPublicIP reports whether an address is globally routable. Active probes refuse
+// other targets unless ALLOW_PRIVATE_TARGETS is set, so the API cannot be used to
+// reach into the network it runs in.
+func isPublicIP(ip net.IP) bool {
+	if strings.EqualFold(getenvDefault("ALLOW_PRIVATE_TARGETS", "false"), "true") {
+		return true
+	}
+	return isRoutableIP(ip)
+}
 
-	// Build robots.txt URL
-	robotsURL := fmt.Sprintf("https://%s/robots.txt", cleanDomain)
+// isRoutableIP reports whether an address is globally routable, ignoring ALLOW_PRIVATE_TARGETS
+func isRoutableIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast())
+}
 
-	// Try HTTP if HTTPS fails
-	resp, err := nc.httpClient.Get(robotsURL)
-	if err != nil {
-		robotsURL = fmt.Sprintf("http://%s/robots.txt", cleanDomain)
-		resp, err = nc.httpClient.Get(robotsURL)
+// resolveTarget resolves a host or IP to a single public address for active probing
+func resolveTarget(host string) (net.IP, error) {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ips, err := net.LookupIP(host)
 		if err != nil {
-			info.Exists = false
-			info.Status = "Not Found"
-			info.Error = fmt.Sprintf("Failed to fetch robots.txt: %v", err)
-			return info
+			return nil, fmt.Errorf("failed to resolve host: %v", err)
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("no IP addresses found for host")
+		}
+		ip = ips[0]
+		for _, candidate := range ips {
+			if candidate.To4() != nil {
+				ip = candidate
+				break
+			}
 		}
 	}
-	defer resp.Body.Close()
-
-	info.Exists = true
-	info.Status = fmt.Sprintf("HTTP %d", resp.StatusCode)
-
-	// Read content
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		info.Error = fmt.Sprintf("Failed to read response: %v", err)
-		return info
+	if !isPublicIP(ip) {
+		return nil, fmt.Errorf("target %s is not a public address", ip)
 	}
+	return ip, nil
+}
 
-	info.Content = string(body)
-	info.Lines = strings.Split(info.Content, "\n")
-
-	// Parse robots.txt content
-	currentUserAgent := "*"
-	for _, line := range info.Lines {
-		line = strings.TrimSpace(line)
-
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+// guardedDialContext returns a dial function that only connects to addresses the
+// allowed check accepts. Checking at connect time covers redirect hops and DNS
+// answers that change after a target was validated.
+func guardedDialContext(allowed func(net.IP) bool) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
 		}
-
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) != 2 {
-			continue
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
 		}
 
-		field := strings.TrimSpace(strings.ToLower(parts[0]))
-		value := strings.TrimSpace(parts[1])
-
-		switch field {
-		case "user-agent":
-			currentUserAgent = value
-			info.UserAgents = append(info.UserAgents, value)
-		case "disallow":
-			if value != "" {
-				info.Disallowed = append(info.Disallowed, fmt.Sprintf("%s: %s", currentUserAgent, value))
-			}
-		case "allow":
-			if value != "" {
-				info.Allowed = append(info.Allowed, fmt.Sprintf("%s: %s", currentUserAgent, value))
-			}
-		case "sitemap":
-			info.Sitemaps = append(info.Sitemaps, value)
-		case "crawl-delay":
-			if value != "" {
-				info.CrawlDelay = value
+		dialer := net.Dialer{Timeout: 10 * time.Second}
+		lastErr := fmt.Errorf("target %s has no public address", host)
+		for _, a := range addrs {
+			if !allowed(a.IP) {
+				lastErr = fmt.Errorf("target %s is not a public address", a.IP)
+				continue
 			}
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(a.IP.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
 		}
+		return nil, lastErr
 	}
+}
 
-	return info
+// guardedHTTPClient returns a copy of the checker's HTTP client that can only reach
+// addresses the allowed check accepts
+func (nc *NetChecker) guardedHTTPClient(allowed func(net.IP) bool) *http.Client {
+	client := *nc.httpClient
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if t, ok := nc.httpClient.Transport.(*http.Transport); ok {
+		transport = t.Clone()
+	}
+	transport.Proxy = nil
+	transport.DialContext = guardedDialContext(allowed)
+	client.Transport = transport
+	return &client
 }
 
-func handleRobotsTxt(c *gin.Context) {
-	domain := c.Query("domain")
-	if domain == "" {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Domain parameter is required",
-		})
-		return
-	}
+// publicHTTPClient returns a client that can only reach public addresses, for URLs
+// that come from callers or remote responses
+func (nc *NetChecker) publicHTTPClient() *http.Client {
+	return nc.guardedHTTPClient(isPublicIP)
+}
 
-	key := cacheKey("/api/v1/robots-txt", map[string]string{"domain": domain})
-	if v, ok := apiCache.Get(key); ok {
-		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
-		return
-	}
-	checker := NewNetChecker()
-	robotsInfo := checker.CheckRobotsTxt(domain)
-	if ttl, ok := routeTTL["/api/v1/robots-txt"]; ok {
-		apiCache.Set(key, robotsInfo, ttl)
+// parsePortList parses a comma separated list of ports and ranges (e.g. "22,80,8000-8010")
+func parsePortList(spec string) ([]int, error) {
+	if strings.TrimSpace(spec) == "" {
+		return defaultScanPorts, nil
 	}
 
-	c.JSON(http.StatusOK, APIResponse{
-		Success: true,
-		Data:    robotsInfo,
-	})
+	var ports []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		bounds := strings.SplitN(part, "-", 2)
+		lo, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", part)
+		}
+		hi := lo
+		if len(bounds) == 2 {
+			if hi, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+				return nil, fmt.Errorf("invalid port range %q", part)
+			}
+		}
+		if lo < 1 || hi > 65535 || lo > hi {
+			return nil, fmt.Errorf("port out of range %q", part)
+		}
+		for p := lo; p <= hi; p++ {
+			if seen[p] {
+				continue
+			}
+			if len(ports) >= maxScanPorts {
+				return nil, fmt.Errorf("too many ports (maximum %d)", maxScanPorts)
+			}
+			seen[p] = true
+			ports = append(ports, p)
+		}
+	}
+	return ports, nil
 }
 
-// CheckSitemap checks sitemap.xml file for a domain
-func (nc *NetChecker) CheckSitemap(domain string) SitemapInfo {
-	info := SitemapInfo{Domain: domain}
-
-	// Clean domain
-	cleanDomain := strings.TrimPrefix(domain, "https://")
-	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
-	cleanDomain = strings.Split(cleanDomain, "/")[0]
+// passiveBannerServices speak first after connect, so their banner is read without sending anything
+var passiveBannerServices = map[string]bool{
+	"ftp":        true,
+	"ssh":        true,
+	"telnet":     true,
+	"smtp":       true,
+	"submission": true,
+	"pop3":       true,
+	"imap":       true,
+	"mysql":      true,
+}
 
-	// Common sitemap locations to try
-	sitemapPaths := []string{
-		"/sitemap.xml",
-		"/sitemap_index.xml",
-		"/sitemap-index.xml",
-		"/sitemaps.xml",
+// grabBanner reads a short banner from an open connection. Services that speak
+// first are read passively; plain HTTP gets a HEAD request.
+func grabBanner(conn net.Conn, host string, port int) string {
+	service := knownServices[port]
+	isHTTP := service == "http" || service == "http-alt"
+	if !isHTTP && !passiveBannerServices[service] {
+		return ""
+	}
+
+	conn.SetDeadline(time.Now().Add(1500 * time.Millisecond))
+	if isHTTP {
+		fmt.Fprintf(conn, "HEAD / HTTP/1.0\r\nHost: %s\r\nUser-Agent: NetCheck-API/1.0\r\n\r\n", host)
+	}
+
+	// Banners are usually shorter than the buffer, so take whatever the first read
+	// returns instead of waiting for the deadline to fill it
+	buf := make([]byte, maxBannerBytes)
+	n, _ := conn.Read(buf)
+	banner := string(buf[:n])
+
+	if isHTTP {
+		// Keep the status line and Server header only
+		var kept []string
+		for i, line := range strings.Split(banner, "\n") {
+			line = strings.TrimSpace(line)
+			if i == 0 || strings.HasPrefix(strings.ToLower(line), "server:") {
+				kept = append(kept, line)
+			}
+		}
+		return strings.Join(kept, " | ")
 	}
 
-	var sitemapURL string
-	var resp *http.Response
-	var err error
+	if idx := strings.IndexAny(banner, "\r\n"); idx != -1 {
+		banner = banner[:idx]
+	}
+	return strings.TrimSpace(banner)
+}
 
-	// Try each sitemap path
-	for _, path := range sitemapPaths {
-		sitemapURL = fmt.Sprintf("https://%s%s", cleanDomain, path)
-		resp, err = nc.httpClient.Get(sitemapURL)
-		if err == nil && resp.StatusCode == 200 {
-			info.SitemapURL = sitemapURL
-			break
-		}
-		if resp != nil {
-			resp.Body.Close()
-		}
+// probePort performs a single TCP connect probe
+func probePort(ip net.IP, host string, port int, timeout time.Duration) PortResult {
+	result := PortResult{Port: port, Service: knownServices[port]}
 
-		// Try HTTP if HTTPS fails
-		sitemapURL = fmt.Sprintf("http://%s%s", cleanDomain, path)
-		resp, err = nc.httpClient.Get(sitemapURL)
-		if err == nil && resp.StatusCode == 200 {
-			info.SitemapURL = sitemapURL
-			break
-		}
-		if resp != nil {
-			resp.Body.Close()
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip.String(), strconv.Itoa(port)), timeout)
+	if err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			result.Status = "closed"
+			result.LatencyMs = time.Since(start).Milliseconds()
+		} else {
+			result.Status = "filtered"
 		}
+		return result
 	}
+	defer conn.Close()
 
-	if resp == nil || err != nil {
-		info.Exists = false
-		info.Status = "Not Found"
-		info.Error = fmt.Sprintf("Failed to find sitemap: %v", err)
-		return info
-	}
-	defer resp.Body.Close()
+	result.Status = "open"
+	result.LatencyMs = time.Since(start).Milliseconds()
+	result.Banner = grabBanner(conn, host, port)
+	return result
+}
 
-	info.Exists = true
-	info.Status = fmt.Sprintf("HTTP %d", resp.StatusCode)
+// CheckPorts probes a bounded list of TCP ports concurrently
+func (nc *NetChecker) CheckPorts(host string, ports []int, timeout time.Duration) PortScanInfo {
+	cleanHost := strings.TrimPrefix(host, "https://")
+	cleanHost = strings.TrimPrefix(cleanHost, "http://")
+	cleanHost = strings.Split(cleanHost, "/")[0]
+	info := PortScanInfo{Host: cleanHost}
 
-	// Read content
-	body, err := io.ReadAll(resp.Body)
+	ip, err := resolveTarget(cleanHost)
 	if err != nil {
-		info.Error = fmt.Sprintf("Failed to read response: %v", err)
+		info.Error = err.Error()
 		return info
 	}
+	info.IP = ip.String()
 
-	// Try to parse as sitemap index first
-	var sitemapIndex SitemapIndex
-	err = xml.Unmarshal(body, &sitemapIndex)
-	if err == nil && len(sitemapIndex.Sitemaps) > 0 {
-		info.IsSitemapIndex = true
-		info.URLCount = len(sitemapIndex.Sitemaps)
-		for _, s := range sitemapIndex.Sitemaps {
-			info.SubSitemaps = append(info.SubSitemaps, s.Loc)
-		}
-		return info
+	if timeout <= 0 || timeout > maxScanTimeout {
+		timeout = defaultScanTimeout
 	}
 
-	// Try to parse as regular sitemap
-	var sitemap Sitemap
-	err = xml.Unmarshal(body, &sitemap)
-	if err == nil && len(sitemap.URLs) > 0 {
-		info.IsSitemapIndex = false
-		info.URLCount = len(sitemap.URLs)
+	info.Ports = make([]PortResult, len(ports))
+	sem := make(chan struct{}, scanConcurrency)
+	var wg sync.WaitGroup
+	for i, port := range ports {
+		wg.Add(1)
+		go func(i, port int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			info.Ports[i] = probePort(ip, cleanHost, port, timeout)
+		}(i, port)
+	}
+	wg.Wait()
 
-		// Get sample URLs (first 10)
-		maxSamples := 10
-		if len(sitemap.URLs) < maxSamples {
-			maxSamples = len(sitemap.URLs)
+	for _, p := range info.Ports {
+		if p.Status == "open" {
+			info.OpenCount++
 		}
-		for i := 0; i < maxSamples; i++ {
-			info.SampleURLs = append(info.SampleURLs, sitemap.URLs[i].Loc)
-			if sitemap.URLs[i].LastMod != "" {
-				info.LastModified = append(info.LastModified, sitemap.URLs[i].LastMod)
-			}
-		}
-		return info
 	}
 
-	// If parsing failed, still return that we found something
-	info.Error = "Sitemap found but could not parse XML structure"
 	return info
 }
 
-func handleSitemap(c *gin.Context) {
-	domain := c.Query("domain")
-	if domain == "" {
+func handlePorts(c *gin.Context) {
+	host := c.Query("host")
+	if host == "" {
+		host = c.Query("domain")
+	}
+	if host == "" {
+		host = c.Query("ip")
+	}
+	if host == "" {
 		c.JSON(http.StatusBadRequest, APIResponse{
 			Success: false,
-			Error:   "Domain parameter is required",
+			Error:   "Host, domain or ip parameter is required",
 		})
 		return
 	}
 
-	key := cacheKey("/api/v1/sitemap", map[string]string{"domain": domain})
+	ports, err := parsePortList(c.Query("ports"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	timeout := defaultScanTimeout
+	if v := c.Query("timeout_ms"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms <= 0 || time.Duration(ms)*time.Millisecond > maxScanTimeout {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("timeout_ms must be between 1 and %d", maxScanTimeout.Milliseconds()),
+			})
+			return
+		}
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	key := cacheKey("/api/v1/ports", map[string]string{"host": host, "ports": c.Query("ports"), "timeout": timeout.String()})
 	if v, ok := apiCache.Get(key); ok {
 		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
 		return
 	}
 	checker := NewNetChecker()
-	sitemapInfo := checker.CheckSitemap(domain)
-	if ttl, ok := routeTTL["/api/v1/sitemap"]; ok {
-		apiCache.Set(key, sitemapInfo, ttl)
+	portInfo := checker.CheckPorts(host, ports, timeout)
+	if ttl, ok := routeTTL["/api/v1/ports"]; ok {
+		apiCache.Set(key, portInfo, ttl)
 	}
 
 	c.JSON(http.StatusOK, APIResponse{
 		Success: true,
-		Data:    sitemapInfo,
+		Data:    portInfo,
 	})
 }
 
-// HTMLProxyResponse represents HTML content fetched from a URL
-type HTMLProxyResponse struct {
-	URL    string `json:"url"`
-	HTML   string `json:"html"`
-	Status int    `json:"status"`
-	Error  string `json:"error,omitempty"`
-}
+// -----------------------------
+// Latency and path diagnostics
+// -----------------------------
 
-// OGImageInfo represents Open Graph image and metadata information
-type OGImageInfo struct {
-	URL    string `json:"url"`
-	Domain string `json:"domain"`
-	Found  bool   `json:"found"`
+// Diagnostics limits
+const (
+	defaultPingCount = 5
+	maxPingCount     = 20
+	pingInterval     = 200 * time.Millisecond
+	pingTimeout      = 2 * time.Second
+	defaultMaxHops   = 20
+	maxTraceHops     = 30
+	hopTimeout       = time.Second
+	tracerouteBase   = 33434
+)
 
-	// OG Image specific
-	ImageURL    string `json:"image_url,omitempty"`
-	ImageURLAlt string `json:"image_url_alt,omitempty"`
-	ImageSecure string `json:"image_secure,omitempty"`
-	ImageWidth  string `json:"image_width,omitempty"`
-	ImageHeight string `json:"image_height,omitempty"`
-	ImageType   string `json:"image_type,omitempty"`
-	Accessible  bool   `json:"accessible"`
-	Status      int    `json:"status,omitempty"`
-	ContentType string `json:"content_type,omitempty"`
-	Size        int64  `json:"size,omitempty"`
+// TCPPingInfo represents repeated TCP-connect round-trip measurements
+type TCPPingInfo struct {
+	Port        int       `json:"port"`
+	Sent        int       `json:"sent"`
+	Received    int       `json:"received"`
+	Refused     int       `json:"refused"`
+	LossPercent float64   `json:"loss_percent"`
+	MinMs       float64   `json:"min_ms"`
+	AvgMs       float64   `json:"avg_ms"`
+	MaxMs       float64   `json:"max_ms"`
+	JitterMs    float64   `json:"jitter_ms"`
+	RTTs        []float64 `json:"rtts_ms"`
+}
 
-	// OG Metadata
-	OGTitle       string `json:"og_title,omitempty"`
-	OGDescription string `json:"og_description,omitempty"`
-	OGType        string `json:"og_type,omitempty"`
-	OGURL         string `json:"og_url,omitempty"`
-	OGSiteName    string `json:"og_site_name,omitempty"`
-	OGLocale      string `json:"og_locale,omitempty"`
+// TracerouteHop represents a single hop on the network path
+type TracerouteHop struct {
+	TTL          int     `json:"ttl"`
+	IP           string  `json:"ip,omitempty"`
+	Hostname     string  `json:"hostname,omitempty"`
+	ASN          uint    `json:"asn,omitempty"`
+	Organization string  `json:"organization,omitempty"`
+	RTTMs        float64 `json:"rtt_ms,omitempty"`
+	Timeout      bool    `json:"timeout"`
+}
 
-	// Twitter Card metadata
-	TwitterCard        string `json:"twitter_card,omitempty"`
-	TwitterSite        string `json:"twitter_site,omitempty"`
-	TwitterCreator     string `json:"twitter_creator,omitempty"`
-	TwitterTitle       string `json:"twitter_title,omitempty"`
-	TwitterDescription string `json:"twitter_description,omitempty"`
-	TwitterImage       string `json:"twitter_image,omitempty"`
-	TwitterImageAlt    string `json:"twitter_image_alt,omitempty"`
+// TracerouteInfo represents the network path to a host
+type TracerouteInfo struct {
+	Protocol string          `json:"protocol"` // tcp, udp
+	MaxHops  int             `json:"max_hops"`
+	Reached  bool            `json:"reached"`
+	Hops     []TracerouteHop `json:"hops"`
+	Error    string          `json:"error,omitempty"`
+}
 
-	// Standard meta tags
-	MetaTitle       string `json:"meta_title,omitempty"`
-	MetaDescription string `json:"meta_description,omitempty"`
+// DiagnosticsInfo represents latency and path diagnostics for a host
+type DiagnosticsInfo struct {
+	Host       string          `json:"host"`
+	IP         string          `json:"ip"`
+	Ping       TCPPingInfo     `json:"ping"`
+	Traceroute *TracerouteInfo `json:"traceroute,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
 
-	// All detected tags
-	AllMetaTags    map[string]string `json:"all_meta_tags,omitempty"`
-	AllTwitterTags map[string]string `json:"all_twitter_tags,omitempty"`
+// roundMs converts a duration to milliseconds with microsecond precision
+func roundMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000.0
+}
 
-	Error string `json:"error,omitempty"`
+// round3 rounds a value to three decimal places
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
 }
 
-func handleHTMLProxy(c *gin.Context) {
-	url := c.Query("url")
-	if url == "" {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "URL parameter is required",
-		})
-		return
+// TCPPing measures TCP connect round-trip times to ip:port
+func (nc *NetChecker) TCPPing(ip net.IP, port, count int) TCPPingInfo {
+	info := TCPPingInfo{Port: port, Sent: count}
+	addr := net.JoinHostPort(ip.String(), strconv.Itoa(port))
+
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(pingInterval)
+		}
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", addr, pingTimeout)
+		rtt := roundMs(time.Since(start))
+		switch {
+		case err == nil:
+			conn.Close()
+		case errors.Is(err, syscall.ECONNREFUSED):
+			// A RST from a closed port is still a round trip to the host
+			info.Refused++
+		default:
+			continue
+		}
+		info.RTTs = append(info.RTTs, rtt)
 	}
 
-	// Clean and normalize URL
-	targetURL := url
-	if !strings.HasPrefix(targetURL, "https://") && !strings.HasPrefix(targetURL, "http://") {
-		targetURL = "https://" + targetURL
+	info.Received = len(info.RTTs)
+	info.LossPercent = round3(float64(info.Sent-info.Received) / float64(info.Sent) * 100)
+	if info.Received == 0 {
+		return info
 	}
 
-	checker := NewNetChecker()
-	resp, err := checker.httpClient.Get(targetURL)
-	if err != nil {
-		// Try HTTP if HTTPS fails
-		if strings.HasPrefix(targetURL, "https://") {
-			targetURL = strings.Replace(targetURL, "https://", "http://", 1)
-			resp, err = checker.httpClient.Get(targetURL)
+	info.MinMs, info.MaxMs = info.RTTs[0], info.RTTs[0]
+	var sum, jitter float64
+	for i, rtt := range info.RTTs {
+		sum += rtt
+		if rtt < info.MinMs {
+			info.MinMs = rtt
 		}
-		if err != nil {
-			c.JSON(http.StatusOK, APIResponse{
-				Success: true,
-				Data: HTMLProxyResponse{
-					URL:   targetURL,
-					Error: fmt.Sprintf("Failed to fetch: %v", err),
-				},
-			})
-			return
+		if rtt > info.MaxMs {
+			info.MaxMs = rtt
+		}
+		if i > 0 {
+			// Mean absolute difference between consecutive samples
+			diff := rtt - info.RTTs[i-1]
+			if diff < 0 {
+				diff = -diff
+			}
+			jitter += diff
 		}
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		c.JSON(http.StatusOK, APIResponse{
-			Success: true,
-			Data: HTMLProxyResponse{
-				URL:   targetURL,
-				Error: fmt.Sprintf("Failed to read response: %v", err),
-			},
-		})
-		return
-	}
+	info.AvgMs = round3(sum / float64(info.Received))
+	if info.Received > 1 {
+		info.JitterMs = round3(jitter / float64(info.Received-1))
+	}
 
-	c.JSON(http.StatusOK, APIResponse{
-		Success: true,
-		Data: HTMLProxyResponse{
-			URL:    targetURL,
-			HTML:   string(body),
-			Status: resp.StatusCode,
-		},
-	})
+	return info
 }
 
-// CheckOGImage checks Open Graph image tags for a URL
-func (nc *NetChecker) CheckOGImage(url string) OGImageInfo {
-	info := OGImageInfo{URL: url}
-
-	// Clean and normalize URL
-	targetURL := url
-	if !strings.HasPrefix(targetURL, "https://") && !strings.HasPrefix(targetURL, "http://") {
-		targetURL = "https://" + targetURL
+// icmpQuote extracts the protocol, destination and ports of the original datagram
+// quoted in an ICMP error message
+func icmpQuote(body icmp.MessageBody) (proto int, dst net.IP, srcPort, dstPort int, ok bool) {
+	var data []byte
+	switch b := body.(type) {
+	case *icmp.TimeExceeded:
+		data = b.Data
+	case *icmp.DstUnreach:
+		data = b.Data
+	default:
+		return 0, nil, 0, 0, false
+	}
+	if len(data) < 20 {
+		return 0, nil, 0, 0, false
 	}
+	ihl := int(data[0]&0x0f) * 4
+	if len(data) < ihl+4 {
+		return 0, nil, 0, 0, false
+	}
+	proto = int(data[9])
+	dst = net.IP(data[16:20])
+	srcPort = int(data[ihl])<<8 | int(data[ihl+1])
+	dstPort = int(data[ihl+2])<<8 | int(data[ihl+3])
+	return proto, dst, srcPort, dstPort, true
+}
 
-	// Extract domain
-	cleanDomain := strings.TrimPrefix(targetURL, "https://")
-	cleanDomain = strings.TrimPrefix(cleanDomain, "http://")
-	cleanDomain = strings.Split(cleanDomain, "/")[0]
-	info.Domain = cleanDomain
+// tcpProbeResult is the outcome of a TCP traceroute probe's handshake
+type tcpProbeResult struct {
+	err error
+	at  time.Time
+}
 
-	// Fetch HTML content
-	resp, err := nc.httpClient.Get(targetURL)
-	if err != nil {
-		// Try HTTP if HTTPS fails
-		if strings.HasPrefix(targetURL, "https://") {
-			targetURL = strings.Replace(targetURL, "https://", "http://", 1)
-			resp, err = nc.httpClient.Get(targetURL)
+// hopReply is a matched response to a traceroute probe
+type hopReply struct {
+	router  net.IP
+	reached bool
+	at      time.Time
+}
+
+// readHopReply waits for an ICMP error quoting our probe, or for the TCP handshake
+// to complete. It returns nil if nothing matched before the deadline.
+func readHopReply(ic *icmp.PacketConn, target net.IP, proto, srcPort, dstPort int, deadline time.Time, done <-chan tcpProbeResult) *hopReply {
+	buf := make([]byte, 1500)
+	for time.Now().Before(deadline) {
+		select {
+		case res := <-done:
+			// TCP handshake finished (or was refused) by the target itself
+			if res.err == nil || errors.Is(res.err, syscall.ECONNREFUSED) {
+				return &hopReply{router: target, reached: true, at: res.at}
+			}
+		default:
+		}
+
+		ic.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+		n, peer, err := ic.ReadFrom(buf)
+		if err != nil {
+			continue
 		}
+		at := time.Now()
+		msg, err := icmp.ParseMessage(1, buf[:n])
 		if err != nil {
-			info.Error = fmt.Sprintf("Failed to fetch URL: %v", err)
-			return info
+			continue
+		}
+		qProto, qDst, qSrc, qDstPort, ok := icmpQuote(msg.Body)
+		if !ok || qProto != proto || !qDst.Equal(target) || qSrc != srcPort || qDstPort != dstPort {
+			continue
 		}
+		hop := net.ParseIP(peer.String())
+		if ipAddr, ok := peer.(*net.IPAddr); ok {
+			hop = ipAddr.IP
+		}
+		return &hopReply{router: hop, reached: hop.Equal(target), at: at}
 	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	if resp.StatusCode != 200 {
-		info.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+// sendUDPProbe sends a single UDP datagram with the given TTL and returns its source port
+func sendUDPProbe(target net.IP, ttl int) (int, func(), error) {
+	conn, err := net.ListenPacket("udp4", "0.0.0.0:0")
+	if err != nil {
+		return 0, nil, err
+	}
+	pc := ipv4.NewPacketConn(conn)
+	if err := pc.SetTTL(ttl); err != nil {
+		conn.Close()
+		return 0, nil, err
+	}
+	dst := &net.UDPAddr{IP: target, Port: tracerouteBase + ttl}
+	if _, err := conn.WriteTo([]byte("NETCHECK"), dst); err != nil {
+		conn.Close()
+		return 0, nil, err
+	}
+	return conn.LocalAddr().(*net.UDPAddr).Port, func() { conn.Close() }, nil
+}
+
+// Traceroute discovers the path to an IPv4 target with increasing-TTL UDP or TCP probes.
+// Reading ICMP replies needs a raw socket, so the API must run with CAP_NET_RAW.
+func (nc *NetChecker) Traceroute(target net.IP, protocol string, port, maxHops int) TracerouteInfo {
+	info := TracerouteInfo{Protocol: protocol, MaxHops: maxHops}
+
+	if target.To4() == nil {
+		info.Error = "Traceroute currently supports IPv4 targets only"
 		return info
 	}
+	target = target.To4()
 
-	// Read HTML content
-	body, err := io.ReadAll(resp.Body)
+	ic, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
 	if err != nil {
-		info.Error = fmt.Sprintf("Failed to read response: %v", err)
+		info.Error = fmt.Sprintf("Failed to open ICMP socket (requires CAP_NET_RAW): %v", err)
 		return info
 	}
+	defer ic.Close()
 
-	htmlContent := string(body)
-	info.AllMetaTags = make(map[string]string)
-	info.AllTwitterTags = make(map[string]string)
-
-	// Parse all OG tags
-	allOGTagsRegex := regexp.MustCompile(`(?i)<meta\s+(?:property|name)=["'](og:[^"']+)["']\s+content=["']([^"']+)["']`)
-	matches := allOGTagsRegex.FindAllStringSubmatch(htmlContent, -1)
-	for _, match := range matches {
-		if len(match) >= 3 {
-			tagName := strings.ToLower(match[1])
-			tagValue := match[2]
-			info.AllMetaTags[tagName] = tagValue
+	for ttl := 1; ttl <= maxHops; ttl++ {
+		hop := TracerouteHop{TTL: ttl}
+		start := time.Now()
+		deadline := start.Add(hopTimeout)
 
-			// Extract specific OG fields
-			switch tagName {
-			case "og:title":
-				info.OGTitle = tagValue
-			case "og:description":
-				info.OGDescription = tagValue
-			case "og:type":
-				info.OGType = tagValue
-			case "og:url":
-				info.OGURL = tagValue
-			case "og:site_name":
-				info.OGSiteName = tagValue
-			case "og:locale":
-				info.OGLocale = tagValue
-			case "og:image":
-				info.ImageURL = tagValue
-				info.Found = true
-			case "og:image:url":
-				info.ImageURLAlt = tagValue
-			case "og:image:secure_url":
-				info.ImageSecure = tagValue
-			case "og:image:width":
-				info.ImageWidth = tagValue
-			case "og:image:height":
-				info.ImageHeight = tagValue
-			case "og:image:type":
-				info.ImageType = tagValue
+		var reply *hopReply
+		if protocol == "udp" {
+			srcPort, closeProbe, err := sendUDPProbe(target, ttl)
+			if err != nil {
+				info.Error = fmt.Sprintf("Failed to send probe: %v", err)
+				return info
 			}
-		}
-	}
-
-	// Parse all Twitter Card tags
-	allTwitterTagsRegex := regexp.MustCompile(`(?i)<meta\s+(?:property|name)=["'](twitter:[^"']+)["']\s+content=["']([^"']+)["']`)
-	twitterMatches := allTwitterTagsRegex.FindAllStringSubmatch(htmlContent, -1)
-	for _, match := range twitterMatches {
-		if len(match) >= 3 {
-			tagName := strings.ToLower(match[1])
-			tagValue := match[2]
-			info.AllTwitterTags[tagName] = tagValue
-
-			// Extract specific Twitter fields
-			switch tagName {
-			case "twitter:card":
-				info.TwitterCard = tagValue
-			case "twitter:site":
-				info.TwitterSite = tagValue
-			case "twitter:creator":
-				info.TwitterCreator = tagValue
-			case "twitter:title":
-				info.TwitterTitle = tagValue
-			case "twitter:description":
-				info.TwitterDescription = tagValue
-			case "twitter:image":
-				if !info.Found {
-					info.ImageURL = tagValue
-					info.Found = true
-				}
-				info.TwitterImage = tagValue
-			case "twitter:image:alt":
-				info.TwitterImageAlt = tagValue
+			reply = readHopReply(ic, target, syscall.IPPROTO_UDP, srcPort, tracerouteBase+ttl, deadline, nil)
+			closeProbe()
+		} else {
+			srcPort, done, err := sendTCPProbe(target, port, ttl)
+			if err != nil {
+				info.Error = fmt.Sprintf("Failed to send probe: %v", err)
+				return info
 			}
+			reply = readHopReply(ic, target, syscall.IPPROTO_TCP, srcPort, port, deadline, done)
 		}
-	}
 
-	// Extract standard meta tags (title and description)
-	metaTitleRegex := regexp.MustCompile(`(?i)<title>([^<]+)</title>`)
-	if match := metaTitleRegex.FindStringSubmatch(htmlContent); len(match) > 1 {
-		info.MetaTitle = strings.TrimSpace(match[1])
-	}
+		if reply == nil {
+			hop.Timeout = true
+			info.Hops = append(info.Hops, hop)
+			continue
+		}
 
-	metaDescRegex := regexp.MustCompile(`(?i)<meta\s+name=["']description["']\s+content=["']([^"']+)["']`)
-	if match := metaDescRegex.FindStringSubmatch(htmlContent); len(match) > 1 {
-		info.MetaDescription = match[1]
+		hop.RTTMs = roundMs(reply.at.Sub(start))
+		hop.IP = reply.router.String()
+		info.Hops = append(info.Hops, hop)
+		if reply.reached {
+			info.Reached = true
+			break
+		}
 	}
 
-	// If still no image found, try standard meta tags
-	if !info.Found {
-		standardImageRegex := regexp.MustCompile(`(?i)<link\s+rel=["']image_src["']\s+href=["']([^"']+)["']`)
-		if match := standardImageRegex.FindStringSubmatch(htmlContent); len(match) > 1 {
-			info.ImageURL = match[1]
-			info.Found = true
+	// Enrich responding hops with reverse DNS and ASN data
+	var wg sync.WaitGroup
+	for i := range info.Hops {
+		if info.Hops[i].IP == "" {
+			continue
 		}
+		wg.Add(1)
+		go func(h *TracerouteHop) {
+			defer wg.Done()
+			if names, err := net.LookupAddr(h.IP); err == nil && len(names) > 0 {
+				h.Hostname = strings.TrimSuffix(names[0], ".")
+			}
+			_, _, _, _, organization, _ := lookupGeoIP(h.IP)
+			if organization != "Unknown" {
+				h.Organization = organization
+			}
+			h.ASN, _ = lookupASN(h.IP)
+		}(&info.Hops[i])
 	}
+	wg.Wait()
 
-	// If we found an image URL, check if it's accessible
-	if info.Found && info.ImageURL != "" {
-		// Resolve relative URLs
-		imageURL := info.ImageURL
-		if strings.HasPrefix(imageURL, "//") {
-			imageURL = "https:" + imageURL
-		} else if strings.HasPrefix(imageURL, "/") {
-			imageURL = fmt.Sprintf("https://%s%s", cleanDomain, imageURL)
-		} else if !strings.HasPrefix(imageURL, "http://") && !strings.HasPrefix(imageURL, "https://") {
-			// Relative URL without leading slash
-			if strings.HasSuffix(targetURL, "/") {
-				imageURL = targetURL + imageURL
-			} else {
-				imageURL = targetURL + "/" + imageURL
-			}
-		}
+	return info
+}
 
-		// Try to fetch the image
-		imgResp, imgErr := nc.httpClient.Get(imageURL)
-		if imgErr != nil {
-			// Try HTTP if HTTPS fails
-			if strings.HasPrefix(imageURL, "https://") {
-				imageURLHTTP := strings.Replace(imageURL, "https://", "http://", 1)
-				var imgRespHTTP *http.Response
-				imgRespHTTP, imgErr = nc.httpClient.Get(imageURLHTTP)
-				if imgErr == nil {
-					imgResp = imgRespHTTP
-				}
-			}
-		}
+// CheckDiagnostics runs TCP ping and, optionally, traceroute against a host
+func (nc *NetChecker) CheckDiagnostics(host string, port, count int, protocol string, maxHops int, trace bool) DiagnosticsInfo {
+	cleanHost := strings.TrimPrefix(host, "https://")
+	cleanHost = strings.TrimPrefix(cleanHost, "http://")
+	cleanHost = strings.Split(cleanHost, "/")[0]
+	info := DiagnosticsInfo{Host: cleanHost}
 
-		if imgErr == nil && imgResp != nil {
-			defer imgResp.Body.Close()
-			info.Accessible = true
-			info.Status = imgResp.StatusCode
-			info.ContentType = imgResp.Header.Get("Content-Type")
-			info.Size = imgResp.ContentLength
-		} else {
-			info.Accessible = false
-			if imgErr != nil {
-				info.Error = fmt.Sprintf("Image not accessible: %v", imgErr)
-			} else {
-				info.Error = "Image not accessible"
-			}
-		}
+	ip, err := resolveTarget(cleanHost)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.IP = ip.String()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		info.Ping = nc.TCPPing(ip, port, count)
+	}()
+	if trace {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tr := nc.Traceroute(ip, protocol, port, maxHops)
+			info.Traceroute = &tr
+		}()
 	}
+	wg.Wait()
 
 	return info
 }
 
-func handleOGImage(c *gin.Context) {
-	url := c.Query("url")
-	domain := c.Query("domain")
+func handleDiagnostics(c *gin.Context) {
+	host := c.Query("host")
+	if host == "" {
+		host = c.Query("domain")
+	}
+	if host == "" {
+		host = c.Query("ip")
+	}
+	if host == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Host, domain or ip parameter is required",
+		})
+		return
+	}
 
-	// Support both url and domain parameters
-	targetURL := url
-	if targetURL == "" && domain != "" {
-		targetURL = domain
+	port, err := strconv.Atoi(c.DefaultQuery("port", "443"))
+	if err != nil || port < 1 || port > 65535 {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "port must be between 1 and 65535",
+		})
+		return
 	}
 
-	if targetURL == "" {
+	count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(defaultPingCount)))
+	if err != nil || count < 1 || count > maxPingCount {
 		c.JSON(http.StatusBadRequest, APIResponse{
 			Success: false,
-			Error:   "URL or domain parameter is required",
+			Error:   fmt.Sprintf("count must be between 1 and %d", maxPingCount),
 		})
 		return
 	}
 
-	key := cacheKey("/api/v1/og-image", map[string]string{"url": targetURL})
+	maxHops, err := strconv.Atoi(c.DefaultQuery("max_hops", strconv.Itoa(defaultMaxHops)))
+	if err != nil || maxHops < 1 || maxHops > maxTraceHops {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("max_hops must be between 1 and %d", maxTraceHops),
+		})
+		return
+	}
+
+	protocol := strings.ToLower(c.DefaultQuery("protocol", "tcp"))
+	if protocol != "tcp" && protocol != "udp" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "protocol must be tcp or udp",
+		})
+		return
+	}
+
+	trace := c.DefaultQuery("traceroute", "true") != "false"
+
+	key := cacheKey("/api/v1/diagnostics", map[string]string{
+		"host": host, "port": strconv.Itoa(port), "count": strconv.Itoa(count),
+		"protocol": protocol, "max_hops": strconv.Itoa(maxHops), "traceroute": strconv.FormatBool(trace),
+	})
 	if v, ok := apiCache.Get(key); ok {
 		c.JSON(http.StatusOK, APIResponse{Success: true, Data: v})
 		return
 	}
-
 	checker := NewNetChecker()
-	ogInfo := checker.CheckOGImage(targetURL)
-	if ttl, ok := routeTTL["/api/v1/og-image"]; ok {
-		apiCache.Set(key, ogInfo, ttl)
+	diagInfo := checker.CheckDiagnostics(host, port, count, protocol, maxHops, trace)
+	if ttl, ok := routeTTL["/api/v1/diagnostics"]; ok {
+		apiCache.Set(key, diagInfo, ttl)
 	}
 
 	c.JSON(http.StatusOK, APIResponse{
 		Success: true,
-		Data:    ogInfo,
+		Data:    diagInfo,
 	})
 }
 
@@ -2186,6 +11595,15 @@ func handleComprehensive(c *gin.Context) {
 		return
 	}
 
+	opts, err := parseWebRequestOptions(c, "HEAD")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid request options: %v", err),
+		})
+		return
+	}
+
 	checker := NewNetChecker()
 
 	// Run all checks in parallel using goroutines (optimized to reduce network requests)
@@ -2201,7 +11619,7 @@ func handleComprehensive(c *gin.Context) {
 	// Combined SSL + WebSettings in a single network request
 	go func() {
 		checkStart := time.Now()
-		combined := checker.CheckSSLAndWebSettings(domain)
+		combined := checker.CheckSSLAndWebSettings(domain, opts)
 		duration := time.Since(checkStart).Milliseconds()
 
 		// Send both results separately
@@ -2225,7 +11643,7 @@ func handleComprehensive(c *gin.Context) {
 
 	go func() {
 		checkStart := time.Now()
-		data := checker.CheckEmailConfig(domain)
+		data := checker.CheckEmailConfig(domain, false)
 		duration := time.Since(checkStart).Milliseconds()
 		resultChan <- result{"email_config", data, duration}
 	}()
@@ -2321,7 +11739,7 @@ func main() {
 			requestOrigin := c.Request.Header.Get("Origin")
 			if requestOrigin != "" {
 				c.Header("Access-Control-Allow-Origin", requestOrigin)
-				c.Header("Access-Control-Allow-Methods", "GET, OPTIONS")
+				c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 				c.Header("Access-Control-Allow-Headers", "Content-Type, X-Internal-Proxy, X-API-Secret, Authorization")
 				c.Header("Access-Control-Allow-Credentials", "true")
 			}
@@ -2374,7 +11792,7 @@ func main() {
 		requestOrigin := c.Request.Header.Get("Origin")
 		if requestOrigin != "" {
 			c.Header("Access-Control-Allow-Origin", requestOrigin)
-			c.Header("Access-Control-Allow-Methods", "GET, OPTIONS")
+			c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 			c.Header("Access-Control-Allow-Headers", "Content-Type, X-Internal-Proxy, X-API-Secret, Authorization")
 			c.Header("Access-Control-Allow-Credentials", "true")
 		}
@@ -2398,12 +11816,31 @@ func main() {
 	api := r.Group("/api/v1")
 	// Rate limiter: default 60 rpm; heavy routes stricter
 	rl := NewRateLimiter(60, map[string]int{
-		"/api/v1/comprehensive": 6,
-		"/api/v1/robots-txt":    10,
-		"/api/v1/sitemap":       10,
-		"/api/v1/blocklist":     10,
-		"/api/v1/web-settings":  20,
-		"/api/v1/og-image":      15,
+		"/api/v1/comprehensive":       6,
+		"/api/v1/robots-txt":          10,
+		"/api/v1/sitemap":             10,
+		"/api/v1/blocklist":           10,
+		"/api/v1/web-settings":        20,
+		"/api/v1/security-headers":    20,
+		"/api/v1/compression-caching": 10,
+		"/api/v1/cors":                10,
+		"/api/v1/http-methods":        10,
+		"/api/v1/well-known":          10,
+		"/api/v1/technologies":        20,
+		"/api/v1/og-image":            15,
+		"/api/v1/ip/bulk":             6,
+		"/api/v1/whois":               10,
+		"/api/v1/ports":               6,
+		"/api/v1/diagnostics":         4,
+		"/api/v1/spf":                 20,
+		"/api/v1/spf/check":           20,
+		"/api/v1/dkim":                10,
+		"/api/v1/dmarc":               10,
+		"/api/v1/dmarc/reports":       6,
+		"/api/v1/bimi":                10,
+		"/api/v1/smtp":                6,
+		"/api/v1/email-headers":       20,
+		"/api/v1/deliverability":      4,
 	})
 	api.Use(rl.Middleware())
 	{
@@ -2412,15 +11849,35 @@ func main() {
 		api.GET("/http3", handleHTTP3)
 		api.GET("/dns", handleDNS)
 		api.GET("/ip", handleIP)
+		api.POST("/ip/bulk", handleIPBulk)
 		api.GET("/my-ip", handleMyIP)
 		api.GET("/web-settings", handleWebSettings)
 		api.GET("/email-config", handleEmailConfig)
+		api.GET("/spf", handleSPF)
+		api.GET("/spf/check", handleSPFCheck)
+		api.GET("/dkim", handleDKIM)
+		api.GET("/dmarc", handleDMARC)
+		api.POST("/dmarc/reports", handleDMARCReportUpload)
+		api.GET("/dmarc/reports", handleDMARCReportTrends)
+		api.GET("/bimi", handleBIMI)
+		api.GET("/smtp", handleSMTP)
+		api.POST("/email-headers", handleEmailHeaders)
+		api.GET("/deliverability", handleDeliverability)
 		api.GET("/blocklist", handleBlocklist)
 		api.GET("/hsts", handleHSTS)
+		api.GET("/security-headers", handleSecurityHeaders)
+		api.GET("/compression-caching", handleCompressionCaching)
+		api.GET("/cors", handleCORS)
+		api.GET("/http-methods", handleHTTPMethods)
+		api.GET("/well-known", handleWellKnown)
+		api.GET("/technologies", handleTechnologies)
 		api.GET("/robots-txt", handleRobotsTxt)
 		api.GET("/sitemap", handleSitemap)
 		api.GET("/og-image", handleOGImage)
 		api.GET("/html-proxy", handleHTMLProxy)
+		api.GET("/whois", handleWhois)
+		api.GET("/ports", handlePorts)
+		api.GET("/diagnostics", handleDiagnostics)
 		api.GET("/comprehensive", handleComprehensive)
 	}
 
@@ -2430,20 +11887,39 @@ func main() {
 			"message": "NetCheck API",
 			"version": "1.0.0",
 			"endpoints": map[string]string{
-				"health":        "GET /api/v1/health",
-				"ssl":           "GET /api/v1/ssl?domain=example.com",
-				"http3":         "GET /api/v1/http3?domain=example.com",
-				"dns":           "GET /api/v1/dns?domain=example.com",
-				"ip":            "GET /api/v1/ip?ip=8.8.8.8 or ?domain=example.com",
-				"my-ip":         "GET /api/v1/my-ip (returns your IP address)",
-				"web-settings":  "GET /api/v1/web-settings?domain=example.com",
-				"email-config":  "GET /api/v1/email-config?domain=example.com",
-				"blocklist":     "GET /api/v1/blocklist?domain=example.com",
-				"hsts":          "GET /api/v1/hsts?domain=example.com",
-				"robots-txt":    "GET /api/v1/robots-txt?domain=example.com",
-				"sitemap":       "GET /api/v1/sitemap?domain=example.com",
-				"og-image":      "GET /api/v1/og-image?url=https://example.com or ?domain=example.com",
-				"comprehensive": "GET /api/v1/comprehensive?domain=example.com",
+				"health":              "GET /api/v1/health",
+				"ssl":                 "GET /api/v1/ssl?domain=example.com",
+				"http3":               "GET /api/v1/http3?domain=example.com",
+				"dns":                 "GET /api/v1/dns?domain=example.com",
+				"ip":                  "GET /api/v1/ip?ip=8.8.8.8 or ?domain=example.com",
+				"ip-bulk":             "POST /api/v1/ip/bulk (JSON {\"inputs\": [...]} or plain text, NDJSON response)",
+				"my-ip":               "GET /api/v1/my-ip (returns your IP address)",
+				"web-settings":        "GET /api/v1/web-settings?domain=example.com",
+				"email-config":        "GET /api/v1/email-config?domain=example.com&probe_mx=true",
+				"spf":                 "GET /api/v1/spf?domain=example.com",
+				"spf-check":           "GET /api/v1/spf/check?domain=example.com&ip=192.0.2.1&helo=mail.example.com&mail_from=bounce@example.com",
+				"dkim":                "GET /api/v1/dkim?domain=example.com&selectors=s2024,mail",
+				"dmarc":               "GET /api/v1/dmarc?domain=example.com",
+				"dmarc-reports":       "POST /api/v1/dmarc/reports (aggregate XML, .gz or .zip); GET /api/v1/dmarc/reports?domain=example.com&days=30",
+				"bimi":                "GET /api/v1/bimi?domain=example.com",
+				"smtp":                "GET /api/v1/smtp?domain=example.com&ports=25,465,587",
+				"email-headers":       "POST /api/v1/email-headers (raw message headers as text, or JSON {\"headers\": \"...\"})",
+				"deliverability":      "GET /api/v1/deliverability?domain=example.com",
+				"blocklist":           "GET /api/v1/blocklist?domain=example.com",
+				"hsts":                "GET /api/v1/hsts?domain=example.com",
+				"security-headers":    "GET /api/v1/security-headers?domain=example.com",
+				"compression-caching": "GET /api/v1/compression-caching?url=https://example.com",
+				"cors":                "GET /api/v1/cors?url=https://api.example.com/resource&origins=https://app.example.com",
+				"http-methods":        "GET /api/v1/http-methods?url=https://example.com/path",
+				"well-known":          "GET /api/v1/well-known?domain=example.com",
+				"technologies":        "GET /api/v1/technologies?domain=example.com",
+				"robots-txt":          "GET /api/v1/robots-txt?domain=example.com",
+				"sitemap":             "GET /api/v1/sitemap?domain=example.com",
+				"og-image":            "GET /api/v1/og-image?url=https://example.com or ?domain=example.com",
+				"whois":               "GET /api/v1/whois?query=example.com (domain, IP or ASN)",
+				"ports":               "GET /api/v1/ports?host=example.com&ports=22,80,443",
+				"diagnostics":         "GET /api/v1/diagnostics?host=example.com&port=443&protocol=tcp",
+				"comprehensive":       "GET /api/v1/comprehensive?domain=example.com",
 			},
 		})
 	})
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resetRDAPBootstrap clears the process-wide bootstrap cache between tests
+func resetRDAPBootstrap(t *testing.T) {
+	t.Helper()
+	rdapBootstrapMu.Lock()
+	rdapBootstrapCache = make(map[string]rdapBootstrapEntry)
+	rdapBootstrapMu.Unlock()
+	t.Cleanup(func() {
+		rdapBootstrapMu.Lock()
+		rdapBootstrapCache = make(map[string]rdapBootstrapEntry)
+		rdapBootstrapMu.Unlock()
+	})
+}
+
+// writeRDAPBootstrap writes a dns bootstrap file mapping tld to baseURL
+func writeRDAPBootstrap(t *testing.T, tld, baseURL string) {
+	t.Helper()
+	dir := t.TempDir()
+	bootstrap := RDAPBootstrap{
+		Version:  "1.0",
+		Services: [][][]string{{{tld}, {baseURL}}},
+	}
+	data, err := json.Marshal(bootstrap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dns.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RDAP_BOOTSTRAP_DIR", dir)
+}
+
+// newRDAPStandIn serves a thin registry record for example.test that links to a
+// registrar record on the same server
+func newRDAPStandIn(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/domain/example.test", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rdap+json")
+		fmt.Fprintf(w, `{
+			"ldhName": "EXAMPLE.TEST",
+			"handle": "D1-TEST",
+			"status": ["client transfer prohibited"],
+			"events": [
+				{"eventAction": "registration", "eventDate": "2001-02-03T04:05:06Z"},
+				{"eventAction": "expiration", "eventDate": "2099-02-03T04:05:06Z"}
+			],
+			"nameservers": [{"ldhName": "NS1.EXAMPLE.TEST."}],
+			"entities": [{
+				"roles": ["registrar"],
+				"vcardArray": ["vcard", [["fn", {}, "text", "Test Registrar"]]],
+				"entities": [{
+					"roles": ["abuse"],
+					"vcardArray": ["vcard", [["email", {}, "text", "abuse@registrar.test"]]]
+				}]
+			}],
+			"links": [{"rel": "related", "type": "application/rdap+json", "href": "%s/registrar/domain/example.test"}]
+		}`, srv.URL)
+	})
+	mux.HandleFunc("/registrar/domain/example.test", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rdap+json")
+		fmt.Fprint(w, `{
+			"ldhName": "example.test",
+			"entities": [{
+				"roles": ["registrant"],
+				"vcardArray": ["vcard", [["fn", {}, "text", "Jane Doe"], ["org", {}, "text", "Example Org"]]]
+			}]
+		}`)
+	})
+	return srv
+}
+
+func TestCheckWhoisRDAP(t *testing.T) {
+	resetRDAPBootstrap(t)
+	srv := newRDAPStandIn(t)
+	writeRDAPBootstrap(t, "test", srv.URL+"/")
+	t.Setenv("ALLOW_PRIVATE_TARGETS", "true")
+
+	info := NewNetChecker().CheckWhois("https://www.example.test/path")
+	if info.Error != "" {
+		t.Fatalf("unexpected error: %s", info.Error)
+	}
+	if info.Source != "rdap" || info.Query != "example.test" || info.Type != "domain" {
+		t.Errorf("source/query/type = %q/%q/%q", info.Source, info.Query, info.Type)
+	}
+	if info.Registrar != "Test Registrar" {
+		t.Errorf("registrar = %q", info.Registrar)
+	}
+	if info.RegistrantOrg != "Example Org" {
+		t.Errorf("registrant organization = %q, want it from the related record", info.RegistrantOrg)
+	}
+	if info.Created != "2001-02-03T04:05:06Z" || info.Expires != "2099-02-03T04:05:06Z" {
+		t.Errorf("created/expires = %q/%q", info.Created, info.Expires)
+	}
+	if info.DaysUntilExpiry == nil || *info.DaysUntilExpiry <= 0 {
+		t.Errorf("days until expiry = %v", info.DaysUntilExpiry)
+	}
+	if len(info.Nameservers) != 1 || info.Nameservers[0] != "ns1.example.test" {
+		t.Errorf("nameservers = %v", info.Nameservers)
+	}
+	if len(info.AbuseEmails) != 1 || info.AbuseEmails[0] != "abuse@registrar.test" {
+		t.Errorf("abuse emails = %v", info.AbuseEmails)
+	}
+}
+
+func TestCheckWhoisRDAPRelatedLinkMustBePublic(t *testing.T) {
+	resetRDAPBootstrap(t)
+	srv := newRDAPStandIn(t)
+	writeRDAPBootstrap(t, "test", srv.URL+"/")
+	t.Setenv("ALLOW_PRIVATE_TARGETS", "false")
+
+	// The registry itself comes from trusted bootstrap data, but the related link
+	// points at a loopback address and must not be followed
+	info := NewNetChecker().CheckWhois("example.test")
+	if info.Error != "" {
+		t.Fatalf("unexpected error: %s", info.Error)
+	}
+	if info.Registrar != "Test Registrar" {
+		t.Errorf("registrar = %q, want the registry data to be kept", info.Registrar)
+	}
+	if info.RegistrantOrg != "" {
+		t.Errorf("registrant organization = %q, related link should have been refused", info.RegistrantOrg)
+	}
+	if len(info.Warnings) != 1 || !strings.Contains(info.Warnings[0], "not a public address") {
+		t.Errorf("warnings = %v", info.Warnings)
+	}
+}
+
+// serveWhois answers every connection on a local listener with the given text
+func serveWhois(t *testing.T, handle func(query string) string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				query, _ := bufio.NewReader(conn).ReadString('\n')
+				fmt.Fprint(conn, handle(strings.TrimSpace(query)))
+			}(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestCheckWhoisFallbackKeepsPartialReferral(t *testing.T) {
+	resetRDAPBootstrap(t)
+	writeRDAPBootstrap(t, "other", "http://127.0.0.1:1/")
+	t.Setenv("ALLOW_PRIVATE_TARGETS", "true")
+
+	// A referral to a port nothing listens on
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadAddr := closed.Addr().String()
+	closed.Close()
+
+	addr := serveWhois(t, func(query string) string {
+		return "domain: EXAMPLE.TEST\r\n" +
+			"Registrar: Upstream Registrar\r\n" +
+			"Creation Date: 2001-02-03T04:05:06Z\r\n" +
+			"Name Server: NS1.EXAMPLE.TEST\r\n" +
+			"Registrar WHOIS Server: whois://" + deadAddr + "\r\n"
+	})
+	t.Setenv("WHOIS_SERVER", addr)
+
+	info := NewNetChecker().CheckWhois("example.test")
+	if info.Error != "" {
+		t.Fatalf("unexpected error: %s", info.Error)
+	}
+	if info.Source != "whois" || info.Server != addr {
+		t.Errorf("source/server = %q/%q", info.Source, info.Server)
+	}
+	if info.Registrar != "Upstream Registrar" || info.Created != "2001-02-03T04:05:06Z" {
+		t.Errorf("registrar/created = %q/%q", info.Registrar, info.Created)
+	}
+	if len(info.Warnings) != 1 || !strings.Contains(info.Warnings[0], deadAddr) {
+		t.Errorf("warnings = %v", info.Warnings)
+	}
+}
+
+func TestParseWhoisServer(t *testing.T) {
+	tests := []struct {
+		in   string
+		want whoisServer
+	}{
+		{"whois.iana.org", whoisServer{addr: "whois.iana.org:43"}},
+		{"whois://whois.arin.net", whoisServer{addr: "whois.arin.net:43"}},
+		{"127.0.0.1:4343", whoisServer{addr: "127.0.0.1:4343"}},
+		{"rwhois://rwhois.example.net:4321/", whoisServer{addr: "rwhois.example.net:4321", rwhois: true}},
+		{"rwhois://rwhois.example.net", whoisServer{addr: "rwhois.example.net:4321", rwhois: true}},
+		{"", whoisServer{}},
+	}
+	for _, tt := range tests {
+		if got := parseWhoisServer(tt.in); got != tt.want {
+			t.Errorf("parseWhoisServer(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQueryWhoisRWhois(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.WriteString(conn, "%rwhois V-1.5:003fff:00 rwhois.example.net\r\n")
+		r := bufio.NewReader(conn)
+		r.ReadString('\n')
+		io.WriteString(conn, "network:Network-Name:EXAMPLE-NET\r\n"+
+			"network:Org-Name;I:Example Hosting\r\n"+
+			"network:Updated:20200102\r\n"+
+			"%ok\r\n")
+		// A real server keeps the session open until -quit
+		r.ReadString('\n')
+	}()
+
+	text, err := queryWhois(whoisServer{addr: ln.Addr().String(), rwhois: true}, "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info WhoisInfo
+	parseWhoisText(&info, text)
+	if info.Name != "EXAMPLE-NET" || info.RegistrantOrg != "Example Hosting" {
+		t.Errorf("name/org = %q/%q from %q", info.Name, info.RegistrantOrg, text)
+	}
+}
+
+// selfSignedCert returns a throwaway certificate for name
+func selfSignedCert(t *testing.T, name string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// serveSMTP runs a one-session SMTP server on a local listener. It offers
+// STARTTLS when asked, speaks TLS from the start in implicit mode, and only
+// advertises AUTH once the session is encrypted. The verbs it received are
+// sent on the returned channel when the session ends.
+func serveSMTP(t *testing.T, implicitTLS, offerSTARTTLS bool) (string, <-chan []string) {
+	t.Helper()
+	config := &tls.Config{Certificates: []tls.Certificate{selfSignedCert(t, "mx.test")}}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	verbs := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+		var received []string
+		defer func() { verbs <- received }()
+
+		encrypted := implicitTLS
+		if implicitTLS {
+			conn = tls.Server(conn, config)
+		}
+		r := bufio.NewReader(conn)
+		io.WriteString(conn, "220 mx.test ESMTP fake\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			verb := strings.ToUpper(strings.Fields(line + " x")[0])
+			received = append(received, verb)
+			switch verb {
+			case "EHLO":
+				reply := "250-mx.test greets you\r\n250-SIZE 35882577\r\n"
+				if offerSTARTTLS && !encrypted {
+					reply += "250-STARTTLS\r\n"
+				}
+				if encrypted {
+					reply += "250-AUTH PLAIN LOGIN\r\n"
+				}
+				io.WriteString(conn, reply+"250 8BITMIME\r\n")
+			case "STARTTLS":
+				io.WriteString(conn, "220 ready to start TLS\r\n")
+				conn = tls.Server(conn, config)
+				r = bufio.NewReader(conn)
+				encrypted = true
+			case "QUIT":
+				io.WriteString(conn, "221 bye\r\n")
+				return
+			default:
+				io.WriteString(conn, "502 not implemented\r\n")
+			}
+		}
+	}()
+	return ln.Addr().String(), verbs
+}
+
+func TestProbeSMTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		port        int
+		implicitTLS bool
+		starttls    bool
+		wantVerbs   []string
+		wantTLS     bool
+		wantAuth    []string
+	}{
+		{"plain without STARTTLS", 25, false, false, []string{"EHLO", "QUIT"}, false, nil},
+		{"STARTTLS upgrade", 25, false, true, []string{"EHLO", "STARTTLS", "EHLO", "QUIT"}, true, []string{"PLAIN", "LOGIN"}},
+		{"implicit TLS on 465", 465, true, false, []string{"EHLO", "QUIT"}, true, []string{"PLAIN", "LOGIN"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, verbs := serveSMTP(t, tt.implicitTLS, tt.starttls)
+			result := probeSMTPAddr("mx.test", addr, tt.port)
+
+			if result.Error != "" {
+				t.Fatalf("unexpected error: %s", result.Error)
+			}
+			if !result.Connected || result.Port != tt.port || result.ImplicitTLS != tt.implicitTLS {
+				t.Errorf("connected/port/implicit = %v/%d/%v", result.Connected, result.Port, result.ImplicitTLS)
+			}
+			if result.Banner != "mx.test ESMTP fake" {
+				t.Errorf("banner = %q", result.Banner)
+			}
+			if result.SizeLimit != 35882577 {
+				t.Errorf("size limit = %d", result.SizeLimit)
+			}
+			if !slices.Equal(result.AuthMechanisms, tt.wantAuth) {
+				t.Errorf("auth mechanisms = %v, want %v", result.AuthMechanisms, tt.wantAuth)
+			}
+			if result.STARTTLS != tt.starttls {
+				t.Errorf("starttls = %v, want %v", result.STARTTLS, tt.starttls)
+			}
+			if got := result.TLSVersion != ""; got != tt.wantTLS {
+				t.Errorf("tls version = %q, want TLS %v", result.TLSVersion, tt.wantTLS)
+			}
+			if tt.wantTLS {
+				// The self-signed certificate is reported, but not trusted
+				if result.Certificate == nil || result.Certificate.Valid || result.Certificate.Error == "" {
+					t.Errorf("certificate = %+v, want an untrusted certificate", result.Certificate)
+				}
+			}
+
+			select {
+			case got := <-verbs:
+				if !slices.Equal(got, tt.wantVerbs) {
+					t.Errorf("server received %v, want %v", got, tt.wantVerbs)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("SMTP session did not end")
+			}
+		})
+	}
+}
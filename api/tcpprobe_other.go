@@ -0,0 +1,14 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+	"net"
+)
+
+// sendTCPProbe needs to set the TTL and read the source port of a raw socket before
+// connecting, which is only implemented for unix systems
+func sendTCPProbe(target net.IP, port, ttl int) (int, <-chan tcpProbeResult, error) {
+	return 0, nil, errors.New("TCP traceroute is not supported on this platform, use protocol=udp")
+}
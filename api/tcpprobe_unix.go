@@ -0,0 +1,59 @@
+//go:build unix
+
+package main
+
+import (
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// sendTCPProbe starts a TCP handshake with the given TTL. The socket is bound before
+// connecting so its source port is known for matching ICMP replies.
+func sendTCPProbe(target net.IP, port, ttl int) (int, <-chan tcpProbeResult, error) {
+	bound := make(chan int, 1)
+	done := make(chan tcpProbeResult, 1)
+
+	dialer := net.Dialer{
+		Timeout: hopTimeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			var sockErr error
+			srcPort := 0
+			err := c.Control(func(fd uintptr) {
+				if sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TTL, ttl); sockErr != nil {
+					return
+				}
+				if sockErr = syscall.Bind(int(fd), &syscall.SockaddrInet4{}); sockErr != nil {
+					return
+				}
+				if sa, err := syscall.Getsockname(int(fd)); err == nil {
+					if sa4, ok := sa.(*syscall.SockaddrInet4); ok {
+						srcPort = sa4.Port
+					}
+				}
+			})
+			bound <- srcPort
+			if err != nil {
+				return err
+			}
+			return sockErr
+		},
+	}
+
+	go func() {
+		conn, err := dialer.Dial("tcp4", net.JoinHostPort(target.String(), strconv.Itoa(port)))
+		at := time.Now()
+		if conn != nil {
+			conn.Close()
+		}
+		done <- tcpProbeResult{err: err, at: at}
+	}()
+
+	select {
+	case srcPort := <-bound:
+		return srcPort, done, nil
+	case <-time.After(hopTimeout):
+		return 0, done, nil
+	}
+}